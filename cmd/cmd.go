@@ -1,12 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
 
 	"github.com/dubbikins/godoc-readme/godoc_readme"
-	"github.com/dubbikins/godoc-readme/godoc_readme/template_functions"
 	"github.com/spf13/cobra"
 )
 
@@ -14,9 +15,38 @@ var recursive bool = true
 var env string
 var confirm_updates bool
 var package_root string
+var template_dir string
+var check bool
+var stdout bool
+var output_name string
+var output_dir string
+var config_file string
+var watch bool
+var jobs int
+var heading_offset int
+var index bool
+var index_name string
+var index_internal bool
+var source_url string
+var run_examples bool
+var collapse_imports bool
+var env_tags []string
 // NOTE(flags): These Flags are used to determine which sections of the README.md file to generate
 
-var flags template_functions.Flags = template_functions.Flags{
+var render godoc_readme.RenderFlag = godoc_readme.RenderDefault
+var skip_empty bool
+
+// The --skip-* flags remove their section from the rendered sections
+var skip_sections = map[godoc_readme.RenderFlag]*bool{
+	godoc_readme.RenderExamples:  new(bool),
+	godoc_readme.RenderFuncs:     new(bool),
+	godoc_readme.RenderConsts:    new(bool),
+	godoc_readme.RenderImports:   new(bool),
+	godoc_readme.RenderTypes:     new(bool),
+	godoc_readme.RenderVars:      new(bool),
+	godoc_readme.RenderFilenames: new(bool),
+	// --skip-all keeps the package documentation, including its alerts
+	godoc_readme.RenderAll &^ godoc_readme.RenderAlerts: new(bool),
 }
 
 // Initializes the CLI flags/Arguments
@@ -42,56 +72,135 @@ func init() {
 		"Specify the environment variables that should be passed to the build system. Example: 'GOOS=linux GOARCH=amd64'",
 	)
 	rootCmd.PersistentFlags().BoolVar(
-		&flags.SkipExamples, 
+		skip_sections[godoc_readme.RenderExamples], 
 		"skip-examples", false,
 		"Skips generating the examples section",
 	)
 	rootCmd.PersistentFlags().BoolVar(
-		&flags.SkipFuncs, 
+		skip_sections[godoc_readme.RenderFuncs], 
 		"skip-funcs", false,
 		"Skips generating the functions section",
 	)
 	rootCmd.PersistentFlags().BoolVar(
-		&flags.SkipConsts, 
+		skip_sections[godoc_readme.RenderConsts], 
 		"skip-consts", false,
 		"Shows generating the consts section",
 	)
 	rootCmd.PersistentFlags().BoolVar(
-		&flags.SkipImports, 
+		skip_sections[godoc_readme.RenderImports], 
 		"skip-imports", false,
 		"Skips generating the imports section",
 	)
 	rootCmd.PersistentFlags().BoolVar(
-		&flags.SkipTypes, 
+		skip_sections[godoc_readme.RenderTypes], 
 		"skip-types", false,
 		"Skips generating the types section",
 	)
 	rootCmd.PersistentFlags().BoolVar(
-		&flags.SkipVars, 
+		skip_sections[godoc_readme.RenderVars], 
 		"skip-vars", false,
 		"Skips generating the vars section",
 	)
 	rootCmd.PersistentFlags().BoolVar(
-		&flags.SkipFilenames, 
+		skip_sections[godoc_readme.RenderFilenames], 
 		"skip-filenames", false,
 		"Skips generating the files section",
 	)
 	rootCmd.PersistentFlags().BoolVar(
-		&flags.SkipEmpty, 
+		&skip_empty, 
 		"skip-empty", false,
 		"Skips generating any type, func, var, const, or method that does not have a doc string",
 	)
 	rootCmd.PersistentFlags().BoolVar(
-		&flags.SkipAll, 
+		skip_sections[godoc_readme.RenderAll &^ godoc_readme.RenderAlerts], 
 		"skip-all", false,
 		"Skips generating all sections besides the package documentation",
 	)
-	
-	// rootCmd.PersistentFlags().StringVarP(
-	// 	&template_filename, 
-	// 	"template", "t", "", 
-	// 	"The template file to use for generating the README.md file",
-	// )
+	rootCmd.PersistentFlags().Var(
+		&render, 
+		"render",
+		"A comma separated list of the sections to render: types, funcs, methods, vars, consts, examples, alerts, notes, imports, filenames, contents, implementations, class_diagram, fields, env_vars, default, all or none; class_diagram and fields are only rendered if they are listed or all is used, fields renders the struct fields as tables instead of code. The --skip-* flags are applied on top of it",
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&template_dir, 
+		"templates", "t", "", 
+		"A directory of *.tmpl partials (i.e. README.tmpl, .Func.tmpl) that override the default partials with the same name; any partial not found in the directory falls back to the default",
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&check, 
+		"check", false,
+		"Renders the README.md files and compares them with the files on disk without writing anything. Prints a diff for each stale file and exits with a non-zero status if any file is out of date",
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&stdout, 
+		"stdout", false,
+		"Writes the generated README.md files to stdout instead of the package directories, each one after a '<!-- godoc-readme: {import path} {file name} -->' line",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&output_name, 
+		"output-name", "",
+		"The file name of the generated README files, i.e. 'API.md' (default \"README.md\")",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&output_dir, 
+		"output-dir", "",
+		"Writes the README files to this directory instead of the package directories, mirroring the package paths relative to the module root",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&config_file, 
+		"config", "",
+		"The config file to read the defaults and per-package options from. The flags are applied on top of the config file (default \".godoc-readme.yaml\" in the module root)",
+	)
+	rootCmd.PersistentFlags().BoolVarP(
+		&watch, 
+		"watch", "w", false,
+		"Keeps running after generating the README.md files and regenerates the README.md of a package whenever its go files change. Changes to the template directory regenerate every package",
+	)
+	rootCmd.PersistentFlags().IntVarP(
+		&jobs, 
+		"jobs", "j", 0,
+		"The number of README.md files that are rendered in parallel, defaults to the number of CPUs. The files are always written and confirmed one at a time, in package order",
+	)
+	rootCmd.PersistentFlags().IntVar(
+		&heading_offset, 
+		"heading-offset", 0,
+		"Added to the level of the '# Heading's in doc comments, which are rendered as '## Heading' in the package doc and '### Heading' in the doc of a type or func by default",
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&index, 
+		"index", false,
+		"Generates a module index in the module root that lists every package with its synopsis, a link to its README.md and the number of exported types and funcs",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&index_name, 
+		"index-name", "",
+		"The file name of the module index (default \"INDEX.md\")",
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&index_internal, 
+		"index-internal", false,
+		"Lists the internal packages in the module index, they're skipped by default",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&source_url, 
+		"source-url", "",
+		"Links the declarations to the hosted source instead of the local file, i.e. 'https://github.com/{owner}/{repo}/blob/{ref}/{path}#L{start}-L{end}'. {owner} and {repo} are read from the origin git remote, {ref} is the tag or commit of HEAD and {path} is relative to the root of the git repository",
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&run_examples, 
+		"run-examples", false,
+		"Runs the examples with 'go test' and renders their actual output. Examples whose output doesn't match their '// Output:' comment are marked with a warning",
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&collapse_imports, 
+		"collapse-imports", false,
+		"Draws the standard library and third-party imports of the import graphs as two grouped nodes instead of a node for each package",
+	)
+	rootCmd.PersistentFlags().StringSliceVar(
+		&env_tags, 
+		"env-tags", nil,
+		"A comma separated list of the struct tags that name the environment variables listed in the env_vars section, i.e. 'env,envconfig' (default env,envconfig)",
+	)
 	//rootCmd.Flags().BoolP("recursive", "r", true, "Recursively search for go packages in the directory and generate a README.md for each package")
 }
 
@@ -102,24 +211,88 @@ var rootCmd = &cobra.Command{
 	Long:  `Generate README.md file for your go project using comments you already write`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// fmt.Println(flags)
-		if readme, err := godoc_readme.NewReadme(func(ro *godoc_readme.ReadmeOptions) {
+		config, err := godoc_readme.LoadConfig(config_file, ".")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if readme, err := godoc_readme.NewReadme(config.Apply, func(ro *godoc_readme.ReadmeOptions) {
 				ro.PackageDir = package_root
 				if recursive {
 					ro.PackageDir = "./..."
 				}
-				ro.Env = strings.Split(env, "")
+				ro.Env = strings.Fields(env)
 				ro.ConfirmUpdates = confirm_updates
-				ro.Flags = flags
+				if cmd.Flags().Changed("render") {
+					ro.Render = render
+				}
+				for section, skip := range skip_sections {
+					if *skip {
+						ro.Render &^= section
+					}
+				}
+				if skip_empty {
+					ro.SkipEmpty = true
+				}
+				ro.Check = check
+				if stdout {
+					ro.Writer = os.Stdout
+				}
+				if output_name != "" {
+					ro.OutputName = output_name
+				}
+				if output_dir != "" {
+					ro.OutputDir = output_dir
+				}
+				if template_dir != "" {
+					ro.TemplateDir = template_dir
+				}
+				if jobs > 0 {
+					ro.Jobs = jobs
+				}
+				if cmd.Flags().Changed("heading-offset") {
+					ro.HeadingOffset = heading_offset
+				}
+				if index {
+					ro.Index = true
+				}
+				if index_name != "" {
+					ro.IndexName = index_name
+				}
+				if index_internal {
+					ro.IndexInternal = true
+				}
+				if source_url != "" {
+					ro.SourceURL = source_url
+				}
+				if run_examples {
+					ro.RunExamples = true
+				}
+				if collapse_imports {
+					ro.CollapseImports = true
+				}
+				if len(env_tags) > 0 {
+					ro.EnvTags = env_tags
+				}
 				
 		}); err != nil {
-				fmt.Println("err")
 				fmt.Fprintln(os.Stderr, err)
 				os.Exit(1)
 		} else {
-			if err = readme.Generate(); err != nil {
-				fmt.Println("Generate err")
+			if err = readme.Generate(); err != nil && !watch {
 				fmt.Fprintln(os.Stderr, err)
 				os.Exit(1)
+			} else if err != nil {
+				// A stale or broken README is fixed by the next change, so keep watching
+				fmt.Fprintln(os.Stderr, err)
+			}
+			if watch {
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer stop()
+				if err = readme.Watch(ctx); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(1)
+				}
 			}
 		}
 	},
@@ -7,18 +7,41 @@ Usage:
   godoc-readme [flags]
 
 Flags:
-  -c, --confirm          Use this flag to confirm overwriting existing README.md files. The default behaviour is to overwrite the file without confirmation. Confirmation also gives you the option to view the diff between the existing and generated file before overwriting it.
-  -h, --help             help for godoc-readme
-  -p, --package string   Specify the pattern for matching packages to generate the README.md files for. Default '' will match current package only
-  -r, --recursive        If set, recursively search for go packages in the directory and generate a README.md for each package; Default will only create a Readme for the package found in the current directory
-      --skip-all         Skips generating all sections besides the package documentation
-      --skip-consts      Shows generating the consts section
-      --skip-empty       Skips generating any type, func, var, const, or method that does not have a doc string
-      --skip-examples    Skips generating the examples section
-      --skip-filenames   Skips generating the files section
-      --skip-funcs       Skips generating the functions section
-      --skip-imports     Skips generating the imports section
-      --skip-types       Skips generating the types section
-      --skip-vars        Skips generating the vars section
+      --check                Renders the README.md files and compares them with the files on disk without writing anything. Prints a diff for each stale file and exits with a non-zero status if any file is out of date
+      --collapse-imports     Draws the standard library and third-party imports of the import graphs as two grouped nodes instead of a node for each package
+      --config string        The config file to read the defaults and per-package options from. The flags are applied on top of the config file (default ".godoc-readme.yaml" in the module root)
+  -c, --confirm              Use this flag to confirm overwriting existing README.md files. The default behaviour is to overwrite the file without confirmation. Confirmation also gives you the option to view the diff between the existing and generated file before overwriting it.
+  -e, --env string           Specify the environment variables that should be passed to the build system. Example: 'GOOS=linux GOARCH=amd64'
+      --env-tags strings     A comma separated list of the struct tags that name the environment variables listed in the env_vars section, i.e. 'env,envconfig' (default env,envconfig)
+      --heading-offset int   Added to the level of the '# Heading's in doc comments, which are rendered as '## Heading' in the package doc and '### Heading' in the doc of a type or func by default
+  -h, --help                 help for godoc-readme
+      --index                Generates a module index in the module root that lists every package with its synopsis, a link to its README.md and the number of exported types and funcs
+      --index-internal       Lists the internal packages in the module index, they're skipped by default
+      --index-name string    The file name of the module index (default "INDEX.md")
+  -j, --jobs int             The number of README.md files that are rendered in parallel, defaults to the number of CPUs. The files are always written and confirmed one at a time, in package order
+      --output-dir string    Writes the README files to this directory instead of the package directories, mirroring the package paths relative to the module root
+      --output-name string   The file name of the generated README files, i.e. 'API.md' (default "README.md")
+  -p, --package string       Specify the pattern for matching packages to generate the README.md files for. Default '' will match current package only
+  -r, --recursive            If set, recursively search for go packages in the directory and generate a README.md for each package; Default will only create a Readme for the package found in the current directory
+      --render sections      A comma separated list of the sections to render: types, funcs, methods, vars, consts, examples, alerts, notes, imports, filenames, contents, implementations, class_diagram, fields, env_vars, default, all or none; class_diagram and fields are only rendered if they are listed or all is used, fields renders the struct fields as tables instead of code. The --skip-* flags are applied on top of it (default default)
+      --run-examples         Runs the examples with 'go test' and renders their actual output. Examples whose output doesn't match their '// Output:' comment are marked with a warning
+      --skip-all             Skips generating all sections besides the package documentation
+      --skip-consts          Shows generating the consts section
+      --skip-empty           Skips generating any type, func, var, const, or method that does not have a doc string
+      --skip-examples        Skips generating the examples section
+      --skip-filenames       Skips generating the files section
+      --skip-funcs           Skips generating the functions section
+      --skip-imports         Skips generating the imports section
+      --skip-types           Skips generating the types section
+      --skip-vars            Skips generating the vars section
+      --source-url string    Links the declarations to the hosted source instead of the local file, i.e. 'https://github.com/{owner}/{repo}/blob/{ref}/{path}#L{start}-L{end}'. {owner} and {repo} are read from the origin git remote, {ref} is the tag or commit of HEAD and {path} is relative to the root of the git repository
+      --stdout               Writes the generated README.md files to stdout instead of the package directories, each one after a '<!-- godoc-readme: {import path} {file name} -->' line
+  -t, --templates string     A directory of *.tmpl partials (i.e. README.tmpl, .Func.tmpl) that override the default partials with the same name; any partial not found in the directory falls back to the default
+  -w, --watch                Keeps running after generating the README.md files and regenerates the README.md of a package whenever its go files change. Changes to the template directory regenerate every package
+
+@godoc-readme{
+	$Excludes => Imports | Filenames
+	$SkipEmpty => true
+}
 */
 package cmd
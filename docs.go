@@ -36,6 +36,71 @@ go install github.com/dubbikins/godoc-readme@latest
 
 TIP(main): Use the `//go:generate godoc-readme -r` directive in your module root to generate a README.md file for your packages when the `go generate` command is run.
 
+TIP(main): Run `godoc-readme -r --watch` while you write your doc comments, the README.md of a package is regenerated every time one of its go files is saved.
+
+TIP(main): Add the `--index` flag to `godoc-readme -r` to also generate an `INDEX.md` in the module root that links to the README.md of every package, grouped by directory.
+
+TIP(main): Add `--render default,class_diagram` to render a mermaid class diagram of the exported types of each package, generated from their fields, methods and embedded types.
+
+TIP(main): Add `--render default,fields` to render the exported fields of each struct as a table with their types, tags and docs instead of the struct's declaration.
+
+TIP(main): The fields tagged with `env:"..."` or `envconfig:"..."` are listed in an Environment Variables section, a reference of the configuration for operators. Use `--env-tags` or `env-tags` in the config file to read other struct tags.
+
+TIP(main): The imports section is a mermaid graph of the packages a package imports and the packages of the module that import it, add `--collapse-imports` to group the standard library and third-party imports into two nodes.
+
+## Package Directives
+
+Each package can customize its own README with a `@godoc-readme{...}` block in its package doc comment, so a single `godoc-readme -r` covers packages that need different sections.
+The block is removed from the rendered documentation.
+
+```go
+// My Package Title
+//
+// @godoc-readme{
+//	$Excludes => Imports | Filenames
+//	$SkipEmpty => true
+// }
+package my_package
+```
+
+The `$Includes`, `$Excludes`, `$Output`, `$Title` and `$SkipEmpty` directives are supported, see the [godoc_readme package](./godoc_readme/README.md) for details.
+
+## Project Configuration
+
+Project wide defaults live in a `.godoc-readme.yaml` file in the module root, use the `--config` flag to read a different file.
+The flags passed to the CLI are applied on top of the file, the `packages` overrides and the package directives are applied on top of both.
+
+```yaml
+templates: ./docs/templates
+skip-sections: [imports, filenames]
+skip-empty: true
+source-url: https://github.com/{owner}/{repo}/blob/{ref}/{path}#L{start}-L{end}
+exclude: [./internal/...]
+packages:
+  ./cmd:
+    sections: [none]
+```
+
+See the [Config type](./godoc_readme/README.md) for all of the options.
+
+## Keeping Hand-Written Content
+
+By default the whole README.md file is overwritten. If you want to keep a hand-written intro, badges or a contributing section, add region markers to your README.md and godoc-readme will only replace the content between them:
+
+```markdown
+# My Project
+
+Hand-written intro that is never touched.
+
+<!-- godoc-readme:start -->
+<!-- godoc-readme:end -->
+```
+
+The unnamed region is filled with the generated README without its title and `DO NOT EDIT` banner, so the hand-written title stays the only one.
+The markers have to be on their own line, markers in a code block or in inline code, like the ones above, are left alone.
+
+Named regions are filled with a single section instead of the whole README, i.e. `<!-- godoc-readme:start:types -->` and `<!-- godoc-readme:end:types -->`. The `body`, `doc`, `contents`, `class_diagram`, `types`, `funcs`, `consts`, `vars`, `env_vars`, `implementations`, `examples`, `notes`, `filenames` and `imports` regions are supported out of the box, add a `region:<name>` partial to your template directory to support your own.
+
 ## Features
 
 ---
@@ -103,10 +168,11 @@ while single-line alerts are great for adding a note to a specific type, func, m
 [^2]: To add line breaks within a footnote, prefix new lines with 2 spaces.
 
 	This is a second line.
+
+@godoc-readme{
+	$Excludes => All
+}
 */
 package main
 
-//go:generate go run main.go --skip-all
-//go:generate go run main.go -p github.com/dubbikins/godoc-readme/cmd --skip-imports --skip-filenames --skip-empty
-//go:generate go run main.go -p github.com/dubbikins/godoc-readme/godoc_readme --skip-imports --skip-filenames --skip-empty
-//go:generate go run main.go -p github.com/dubbikins/godoc-readme/godoc_readme/template_functions --skip-types --skip-imports --skip-filenames --skip-empty
+//go:generate go run main.go -r
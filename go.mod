@@ -4,8 +4,10 @@ go 1.23.1
 
 require (
 	github.com/dubbikins/envy v0.0.5
+	github.com/fsnotify/fsnotify v1.8.0
 	github.com/spf13/cobra v1.8.1
 	golang.org/x/tools v0.24.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,284 @@
+package godoc_readme
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the name of the project config file that is looked up in the module root
+const ConfigFileName = ".godoc-readme.yaml"
+
+/*
+Config is the project configuration read from a `.godoc-readme.yaml` file in the module root.
+The top level options are the defaults for every package, the `packages` map overrides them for the packages matching its keys.
+
+	templates: ./docs/templates
+	sections: [types, funcs, consts, vars, examples]
+	skip-sections: [filenames]
+	skip-empty: true
+	output-name: README.md
+	exclude:
+	  - ./internal/...
+	packages:
+	  ./cmd:
+	    sections: [none]
+	  github.com/owner/repo/api/...:
+	    output-name: API.md
+	    title: The API
+
+The options are applied in the following order, each one overriding the ones before it:
+
+1. The environment variables, i.e. `GODOC_README_RENDER`
+2. The top level options of the config file
+3. The flags passed to the CLI
+4. The `packages` overrides of the config file
+5. The package's own `@godoc-readme{...}` [Directives]
+
+The package patterns in `include`, `exclude` and the `packages` keys are go package patterns where `...` matches any string, i.e. `./internal/...`.
+A pattern that starts with `.` is matched against the package directory relative to the module root, any other pattern is matched against the import path.
+Relative `templates` and `output-dir` paths are resolved relative to the directory of the config file.
+*/
+type Config struct {
+	ConfigOptions `yaml:",inline"`
+	// Include, if set, limits the generated READMEs to the packages matching any of the patterns
+	Include []string `yaml:"include"`
+	// Exclude skips the packages matching any of the patterns
+	Exclude []string `yaml:"exclude"`
+	// Packages overrides the options for the packages matching the pattern of the key
+	// When more than one pattern matches a package, the longer pattern is applied last
+	Packages map[string]ConfigOptions `yaml:"packages"`
+	// FileName is the path of the config file the config was read from
+	FileName string `yaml:"-"`
+}
+
+// ConfigOptions are the README options that can be set in a config file, an empty value leaves the option unchanged
+type ConfigOptions struct {
+	// TemplateDir is a directory of `*.tmpl` partials, see the `TemplateDir` option of [ReadmeOptions]
+	TemplateDir string `yaml:"templates"`
+	// Sections replaces the rendered sections, see [RenderFlag] for the section names
+	Sections []string `yaml:"sections"`
+	// SkipSections removes the sections from the rendered sections
+	SkipSections []string `yaml:"skip-sections"`
+	SkipEmpty    *bool    `yaml:"skip-empty"`
+	OutputName   string   `yaml:"output-name"`
+	OutputDir    string   `yaml:"output-dir"`
+	Title        string   `yaml:"title"`
+	SourceURL    string   `yaml:"source-url"`
+	RunExamples  *bool    `yaml:"run-examples"`
+	// CollapseImports groups the standard library and third-party imports of the import graphs, see the `CollapseImports` option of [ReadmeOptions]
+	CollapseImports *bool `yaml:"collapse-imports"`
+	// EnvTags replaces the struct tags that name environment variables, see the `EnvTags` option of [ReadmeOptions]
+	EnvTags []string `yaml:"env-tags"`
+}
+
+// LoadConfig reads the config file at *file_name*
+// If *file_name* is empty, the `.godoc-readme.yaml` file in the root of the module containing *dir* is read instead,
+// and a nil config is returned if there's no such file
+func LoadConfig(file_name string, dir string) (config *Config, err error) {
+	if file_name == "" {
+		var module_dir string
+		if module_dir, err = find_module_root(dir); err != nil || module_dir == "" {
+			return
+		}
+		file_name = filepath.Join(module_dir, ConfigFileName)
+		if _, err = os.Stat(file_name); errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+	}
+	var content []byte
+	if content, err = os.ReadFile(file_name); err != nil {
+		return
+	}
+	config = &Config{}
+	var decoder = yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err = decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", file_name, err)
+	}
+	if config.FileName, err = filepath.Abs(file_name); err != nil {
+		return nil, err
+	}
+	// Resolve the paths and validate the section names up front so a typo is reported before anything is generated
+	var config_dir = filepath.Dir(config.FileName)
+	config.ConfigOptions.resolve(config_dir)
+	if err = config.ConfigOptions.apply(&ReadmeOptions{}); err != nil {
+		return nil, fmt.Errorf("%s: %w", file_name, err)
+	}
+	for pattern, options := range config.Packages {
+		options.resolve(config_dir)
+		if err = options.apply(&ReadmeOptions{}); err != nil {
+			return nil, fmt.Errorf("%s: packages %q: %w", file_name, pattern, err)
+		}
+		config.Packages[pattern] = options
+	}
+	return
+}
+
+// find_module_root returns the first directory containing a `go.mod` file, starting at *dir* and walking up to the root of the file system
+// It returns an empty string if no `go.mod` file is found
+func find_module_root(dir string) (module_dir string, err error) {
+	if dir, err = filepath.Abs(dir); err != nil {
+		return
+	}
+	for {
+		if _, err = os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		var parent = filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Apply sets the top level options of the config and keeps the config in the options for the per-package settings
+// It's a [NewReadme] options function, i.e. `NewReadme(config.Apply, ...)`, and does nothing for a nil config
+func (config *Config) Apply(options *ReadmeOptions) {
+	if config == nil {
+		return
+	}
+	options.Config = config
+	// The options were validated when the config was loaded
+	_ = config.ConfigOptions.apply(options)
+}
+
+// includes reports whether a README is generated for the package
+func (config *Config) includes(pkg *packages.Package) bool {
+	if config == nil {
+		return true
+	}
+	if len(config.Include) > 0 && !match_any_package_pattern(config.Include, pkg) {
+		return false
+	}
+	return !match_any_package_pattern(config.Exclude, pkg)
+}
+
+// apply_package applies the `packages` overrides that match the package, the shortest pattern first
+func (config *Config) apply_package(pkg *packages.Package, options *ReadmeOptions) (err error) {
+	if config == nil {
+		return
+	}
+	var patterns []string
+	for pattern := range config.Packages {
+		if match_package_pattern(pattern, pkg) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) < len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	for _, pattern := range patterns {
+		var package_options = config.Packages[pattern]
+		if err = package_options.apply(options); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// resolve makes the relative paths of the options relative to *dir*
+func (config_options *ConfigOptions) resolve(dir string) {
+	if config_options.TemplateDir != "" && !filepath.IsAbs(config_options.TemplateDir) {
+		config_options.TemplateDir = filepath.Join(dir, config_options.TemplateDir)
+	}
+	if config_options.OutputDir != "" && !filepath.IsAbs(config_options.OutputDir) {
+		config_options.OutputDir = filepath.Join(dir, config_options.OutputDir)
+	}
+}
+
+// apply overrides the options with the config options that are set
+func (config_options *ConfigOptions) apply(options *ReadmeOptions) (err error) {
+	var sections RenderFlag
+	if len(config_options.Sections) > 0 {
+		if sections, err = ParseRenderFlag(strings.Join(config_options.Sections, ",")); err != nil {
+			return
+		}
+		options.Render = sections
+	}
+	if len(config_options.SkipSections) > 0 {
+		if sections, err = ParseRenderFlag(strings.Join(config_options.SkipSections, ",")); err != nil {
+			return
+		}
+		options.Render &^= sections
+	}
+	if config_options.SkipEmpty != nil {
+		options.SkipEmpty = *config_options.SkipEmpty
+	}
+	if config_options.TemplateDir != "" {
+		options.TemplateDir = config_options.TemplateDir
+	}
+	if config_options.OutputName != "" {
+		options.OutputName = config_options.OutputName
+	}
+	if config_options.OutputDir != "" {
+		options.OutputDir = config_options.OutputDir
+	}
+	if config_options.Title != "" {
+		options.Title = config_options.Title
+	}
+	if config_options.SourceURL != "" {
+		options.SourceURL = config_options.SourceURL
+	}
+	if config_options.RunExamples != nil {
+		options.RunExamples = *config_options.RunExamples
+	}
+	if config_options.CollapseImports != nil {
+		options.CollapseImports = *config_options.CollapseImports
+	}
+	if len(config_options.EnvTags) > 0 {
+		options.EnvTags = config_options.EnvTags
+	}
+	return
+}
+
+func match_any_package_pattern(patterns []string, pkg *packages.Package) bool {
+	for _, pattern := range patterns {
+		if match_package_pattern(pattern, pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+// match_package_pattern reports whether the package matches a go package pattern, i.e. `./internal/...` or `github.com/owner/repo/...`
+// A pattern that starts with `.` is matched against the package directory relative to the module root, any other pattern is matched against the import path
+func match_package_pattern(pattern string, pkg *packages.Package) bool {
+	var name = pkg.PkgPath
+	if strings.HasPrefix(pattern, ".") {
+		if pkg.Module == nil || len(pkg.GoFiles) == 0 {
+			return false
+		}
+		rel_dir, err := filepath.Rel(pkg.Module.Dir, filepath.Dir(pkg.GoFiles[0]))
+		if err != nil {
+			return false
+		}
+		name = filepath.ToSlash(rel_dir)
+		if pattern = strings.TrimPrefix(pattern, "./"); pattern == "" {
+			pattern = "."
+		}
+	}
+	return match_pattern(pattern, name)
+}
+
+// match_pattern matches a name against a pattern where `...` matches any string, a trailing `/...` also matches the name without it
+func match_pattern(pattern string, name string) bool {
+	var expr = strings.ReplaceAll(regexp.QuoteMeta(pattern), `\.\.\.`, `.*`)
+	if strings.HasSuffix(expr, `/.*`) {
+		expr = strings.TrimSuffix(expr, `/.*`) + `(/.*)?`
+	}
+	return regexp.MustCompile(`^` + expr + `$`).MatchString(name)
+}
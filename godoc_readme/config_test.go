@@ -0,0 +1,117 @@
+package godoc_readme
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func write_config(t *testing.T, config string) (module_dir string) {
+	module_dir = t.TempDir()
+	if err := os.WriteFile(filepath.Join(module_dir, "go.mod"), []byte("module example.com/project\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(module_dir, ConfigFileName), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func test_package(module_dir string, pkg_path string, rel_dir string) *packages.Package {
+	return &packages.Package{
+		PkgPath: pkg_path,
+		GoFiles: []string{filepath.Join(module_dir, rel_dir, "file.go")},
+		Module:  &packages.Module{Path: "example.com/project", Dir: module_dir},
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	module_dir := write_config(t, `
+templates: ./templates
+sections: [types, funcs, examples]
+skip-empty: true
+exclude: [./internal/...]
+packages:
+  ./api/...:
+    output-name: API.md
+  ./api/v2:
+    sections: [none]
+    title: Version 2
+`)
+	sub_dir := filepath.Join(module_dir, "api", "v2")
+	if err := os.MkdirAll(sub_dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig("", sub_dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config == nil {
+		t.Fatal("expected the config in the module root to be found")
+	}
+
+	var options = ReadmeOptions{Render: RenderAll, OutputName: "README.md"}
+	config.Apply(&options)
+	if options.Render != RenderTypes|RenderFuncs|RenderExamples || !options.SkipEmpty || options.Config != config {
+		t.Errorf("unexpected options %+v", options)
+	}
+	if want := filepath.Join(module_dir, "templates"); options.TemplateDir != want {
+		t.Errorf("have %q, want the template dir relative to the config file %q", options.TemplateDir, want)
+	}
+
+	if config.includes(test_package(module_dir, "example.com/project/internal/db", "internal/db")) {
+		t.Errorf("expected the internal package to be excluded")
+	}
+	var api_v2 = test_package(module_dir, "example.com/project/api/v2", "api/v2")
+	if !config.includes(api_v2) {
+		t.Errorf("expected the api package to be included")
+	}
+	if err = config.apply_package(api_v2, &options); err != nil {
+		t.Fatal(err)
+	}
+	if options.OutputName != "API.md" || options.Title != "Version 2" || options.Render != RenderNone {
+		t.Errorf("expected both package overrides to be applied, got %+v", options)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	if config, err := LoadConfig("", t.TempDir()); config != nil || err != nil {
+		t.Errorf("expected no config and no error without a module, got %v, %v", config, err)
+	}
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"), ""); err == nil {
+		t.Errorf("expected an error for a missing config file")
+	}
+	for _, config := range []string{"sections: [typez]\n", "unknown: true\n", "packages:\n  ./cmd:\n    skip-sections: [nope]\n"} {
+		if _, err := LoadConfig("", write_config(t, config)); err == nil {
+			t.Errorf("expected an error for the config %q", config)
+		}
+	}
+}
+
+func TestMatchPackagePattern(t *testing.T) {
+	module_dir := t.TempDir()
+	var root = test_package(module_dir, "example.com/project", ".")
+	var nested = test_package(module_dir, "example.com/project/cmd/tool", "cmd/tool")
+	for _, test := range []struct {
+		pattern string
+		pkg     *packages.Package
+		match   bool
+	}{
+		{".", root, true},
+		{".", nested, false},
+		{"./...", root, true},
+		{"./...", nested, true},
+		{"./cmd/...", nested, true},
+		{"./cmd", nested, false},
+		{"example.com/project/...", root, true},
+		{"example.com/project/cmd/...", nested, true},
+		{"example.com/project/cmd/...", root, false},
+		{"example.com/...tool", nested, true},
+	} {
+		if have := match_package_pattern(test.pattern, test.pkg); have != test.match {
+			t.Errorf("%q matching %q: have %v, want %v", test.pattern, test.pkg.PkgPath, have, test.match)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package godoc_readme
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// The number of unchanged lines shown before and after each change in a unified diff
+const unified_diff_context = 3
+
+type diff_line struct {
+	op   diffmatchpatch.Operation
+	text string
+}
+
+// unified_diff returns a line based unified diff between the *from* and *to* text, or an empty string if they're equal
+// The names are used in the `---` and `+++` file headers of the diff
+func unified_diff(from_name, to_name, from, to string) string {
+	if from == to {
+		return ""
+	}
+	// Every distinct line is encoded as a single rune so that the diff is computed line by line
+	var line_runes = map[string]rune{}
+	var rune_lines = map[rune]string{}
+	encode := func(text string) (encoded []rune) {
+		for _, line := range strings.SplitAfter(text, "\n") {
+			if line == "" {
+				continue
+			}
+			r, found := line_runes[line]
+			if !found {
+				r = rune(len(line_runes) + 1)
+				if r >= 0xD800 {
+					r += 0x800 // skip the surrogate range so the rune survives the conversion to a string
+				}
+				line_runes[line] = r
+				rune_lines[r] = line
+			}
+			encoded = append(encoded, r)
+		}
+		return
+	}
+	dmp := diffmatchpatch.New()
+	diffs := dmp.DiffMainRunes(encode(from), encode(to), false)
+
+	var lines []diff_line
+	for _, diff := range diffs {
+		for _, r := range diff.Text {
+			lines = append(lines, diff_line{op: diff.Type, text: strings.TrimSuffix(rune_lines[r], "\n")})
+		}
+	}
+
+	var buf = bytes.NewBuffer(nil)
+	buf.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", from_name, to_name))
+	for start := 0; start < len(lines); {
+		// find the next change, then extend the hunk until the gap between changes is larger than the context on both sides
+		first_change := start
+		for first_change < len(lines) && lines[first_change].op == diffmatchpatch.DiffEqual {
+			first_change++
+		}
+		if first_change == len(lines) {
+			break
+		}
+		last_change := first_change
+		for i := first_change; i < len(lines); i++ {
+			if lines[i].op == diffmatchpatch.DiffEqual {
+				if i-last_change > 2*unified_diff_context {
+					break
+				}
+				continue
+			}
+			last_change = i
+		}
+		hunk_start := max(first_change-unified_diff_context, start)
+		hunk_end := min(last_change+unified_diff_context+1, len(lines))
+		write_hunk(buf, lines, hunk_start, hunk_end)
+		start = hunk_end
+	}
+	return buf.String()
+}
+
+// write_hunk writes the `@@ -l,s +l,s @@` header and the lines[start:end] of a unified diff hunk to the buffer
+func write_hunk(buf *bytes.Buffer, lines []diff_line, start, end int) {
+	var from_line, to_line, from_count, to_count int
+	for _, line := range lines[:start] {
+		if line.op != diffmatchpatch.DiffInsert {
+			from_line++
+		}
+		if line.op != diffmatchpatch.DiffDelete {
+			to_line++
+		}
+	}
+	for _, line := range lines[start:end] {
+		if line.op != diffmatchpatch.DiffInsert {
+			from_count++
+		}
+		if line.op != diffmatchpatch.DiffDelete {
+			to_count++
+		}
+	}
+	// An empty range starts at the line before it, otherwise line numbers are 1-based
+	if from_count > 0 {
+		from_line++
+	}
+	if to_count > 0 {
+		to_line++
+	}
+	buf.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", from_line, from_count, to_line, to_count))
+	for _, line := range lines[start:end] {
+		switch line.op {
+		case diffmatchpatch.DiffDelete:
+			buf.WriteString("-")
+		case diffmatchpatch.DiffInsert:
+			buf.WriteString("+")
+		default:
+			buf.WriteString(" ")
+		}
+		buf.WriteString(line.text)
+		buf.WriteString("\n")
+	}
+}
@@ -0,0 +1,122 @@
+package godoc_readme
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+Directives are the README settings a package declares for itself with a `@godoc-readme{...}` block in its package doc comment.
+The block is removed from the package doc before the README is rendered.
+
+	@godoc-readme{
+		$Includes => Types | Funcs | Vars | Consts
+		$Excludes => Imports | Filenames
+		$Output => API.md
+		$Title => My Package
+		$SkipEmpty => true
+	}
+
+Each line of the block is a `$Key => value` pair, pairs can also be separated with a `;` to write the block on a single line.
+The following keys are supported:
+
+| Key | Value | Description |
+| --- | --- | --- |
+| `$Includes` | `\|` separated section names | Only the listed sections are rendered |
+| `$Excludes` | `\|` separated section names | The listed sections are not rendered |
+| `$Output` | file name | The file name of the package's README, i.e. `API.md` |
+| `$Title` | text | Overrides the title of the README, which is the first line of the package doc by default |
+| `$SkipEmpty` | `true` or `false` | Skips any type, func, var, const or method that doesn't have a doc string |
+
+The section names are the [RenderFlag] names, i.e. `Types`, `Funcs`, `Methods` or `All`, optionally prefixed with `Include`, i.e. `IncludeTypes`.
+*/
+type Directives struct {
+	Includes  []string
+	Excludes  []string
+	Output    string
+	Title     string
+	SkipEmpty *bool
+}
+
+// The block must start at the beginning of a line, so a block quoted in a code example isn't mistaken for the package's own directives
+var directive_block_pattern = regexp.MustCompile(`(?ms)^[ \t]*@godoc-readme\{(.*?)\}[ \t]*\n?`)
+
+// ParseDirectives parses the `@godoc-readme{...}` block in a package doc comment
+// It returns the directives, or nil if the doc doesn't contain a block, and the doc with the block removed
+func ParseDirectives(doc string) (directives *Directives, stripped string, err error) {
+	var match = directive_block_pattern.FindStringSubmatchIndex(doc)
+	if match == nil {
+		return nil, doc, nil
+	}
+	stripped = doc[:match[0]] + doc[match[1]:]
+	directives = &Directives{}
+	for _, line := range strings.FieldsFunc(doc[match[2]:match[3]], func(r rune) bool { return r == '\n' || r == ';' }) {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=>")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !found || !strings.HasPrefix(key, "$") {
+			return nil, doc, fmt.Errorf("invalid @godoc-readme directive %q, directives must be written as `$Key => value`", line)
+		}
+		switch strings.ToLower(key[1:]) {
+		case "includes", "include":
+			directives.Includes = append(directives.Includes, split_directive_list(value)...)
+		case "excludes", "exclude":
+			directives.Excludes = append(directives.Excludes, split_directive_list(value)...)
+		case "output":
+			directives.Output = value
+		case "title":
+			directives.Title = value
+		case "skipempty", "skip-empty", "skip_empty":
+			var skip_empty = true
+			if value != "" {
+				if skip_empty, err = strconv.ParseBool(value); err != nil {
+					return nil, doc, fmt.Errorf("invalid @godoc-readme directive %q: %w", line, err)
+				}
+			}
+			directives.SkipEmpty = &skip_empty
+		default:
+			return nil, doc, fmt.Errorf("unknown @godoc-readme directive %q", key)
+		}
+	}
+	return
+}
+
+func split_directive_list(value string) (list []string) {
+	for _, item := range strings.Split(value, "|") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return
+}
+
+// apply overrides the options with the directives
+func (directives *Directives) apply(options *ReadmeOptions) (err error) {
+	var sections RenderFlag
+	if len(directives.Includes) > 0 {
+		if sections, err = ParseRenderFlag(strings.Join(directives.Includes, ",")); err != nil {
+			return
+		}
+		options.Render = sections
+	}
+	if len(directives.Excludes) > 0 {
+		if sections, err = ParseRenderFlag(strings.Join(directives.Excludes, ",")); err != nil {
+			return
+		}
+		options.Render &^= sections
+	}
+	if directives.Output != "" {
+		options.OutputName = directives.Output
+	}
+	if directives.Title != "" {
+		options.Title = directives.Title
+	}
+	if directives.SkipEmpty != nil {
+		options.SkipEmpty = *directives.SkipEmpty
+	}
+	return
+}
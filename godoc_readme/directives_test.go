@@ -0,0 +1,68 @@
+package godoc_readme
+
+import (
+	"testing"
+)
+
+func TestParseDirectives(t *testing.T) {
+	directives, stripped, err := ParseDirectives("Title\n\nSome docs\n\n@godoc-readme{\n\t$Includes => IncludeTypes | Funcs\n\t$Output => API.md\n\t$Title => My Title\n\t$SkipEmpty => true\n}\nMore docs\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Title\n\nSome docs\n\nMore docs\n"; stripped != want {
+		t.Errorf("have %q, want %q", stripped, want)
+	}
+	if len(directives.Includes) != 2 || directives.Includes[0] != "IncludeTypes" || directives.Includes[1] != "Funcs" {
+		t.Errorf("unexpected includes %q", directives.Includes)
+	}
+	if directives.Output != "API.md" || directives.Title != "My Title" || directives.SkipEmpty == nil || !*directives.SkipEmpty {
+		t.Errorf("unexpected directives %+v", directives)
+	}
+
+	var options = ReadmeOptions{OutputName: "README.md", Render: RenderAll}
+	if err = directives.apply(&options); err != nil {
+		t.Fatal(err)
+	}
+	if options.Render != RenderTypes|RenderFuncs {
+		t.Errorf("expected only types and funcs to be included, got %s", options.Render)
+	}
+	if options.OutputName != "API.md" || options.Title != "My Title" || !options.SkipEmpty {
+		t.Errorf("unexpected options %+v", options)
+	}
+}
+
+func TestParseDirectivesSingleLine(t *testing.T) {
+	directives, stripped, err := ParseDirectives("Title\n@godoc-readme{ $Excludes => All; $Title => Other }\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stripped != "Title\n" {
+		t.Errorf("have %q, want %q", stripped, "Title\n")
+	}
+	var options = ReadmeOptions{Render: RenderAll}
+	if err = directives.apply(&options); err != nil {
+		t.Fatal(err)
+	}
+	if options.Render != RenderNone || options.Title != "Other" {
+		t.Errorf("unexpected options %+v", options)
+	}
+}
+
+func TestParseDirectivesErrors(t *testing.T) {
+	if directives, stripped, err := ParseDirectives("No directives\n\t// @godoc-readme{ $Title => Quoted }\n"); err != nil || directives != nil || stripped != "No directives\n\t// @godoc-readme{ $Title => Quoted }\n" {
+		t.Errorf("expected a quoted block to be ignored, got %+v %q %v", directives, stripped, err)
+	}
+	for _, doc := range []string{
+		"@godoc-readme{ $Unknown => value }",
+		"@godoc-readme{ Title => value }",
+		"@godoc-readme{ $SkipEmpty => maybe }",
+	} {
+		if _, _, err := ParseDirectives(doc); err == nil {
+			t.Errorf("expected an error for %q", doc)
+		}
+	}
+	directives, _, _ := ParseDirectives("@godoc-readme{ $Includes => Everything }")
+	if err := directives.apply(&ReadmeOptions{}); err == nil {
+		t.Errorf("expected an error for an unknown section")
+	}
+}
@@ -3,14 +3,20 @@ Package `godoc_readme`
 
 Implements the godocs parsing and README generation from template files.
 
-*/
-package godoc_readme
+The doc comments are parsed with the godoc parser and printed as markdown, so godoc headings, lists, code blocks and doc links are converted while the markdown written in a doc comment is kept as-is.
+Every type, func, method, const and var heading has an `<a id="...">` anchor named after the symbol's qualified name, i.e. `Readme.Generate`, so links to it don't break when the headings change.
+Doc links to the package's own symbols link to these anchors, links to the other packages of the module link to their READMEs and any other doc link points to pkg.go.dev.
 
-/*
-Directives
+The sections of a README are selected with a [RenderFlag]. Besides the declarations, they include the typed constants of a type as a table of their evaluated values,
+the environment variables the structs are filled from, a mermaid graph of the imports and the implementations of the interfaces across the module.
+
+If an existing README has region markers, i.e. `<!-- godoc-readme:start -->` and `<!-- godoc-readme:end -->` on their own lines, only the content between them is replaced.
+A named region, i.e. `<!-- godoc-readme:start:types -->`, is filled with the `region:<name>` partial and the unnamed region with the README without its title.
 
 @godoc-readme{
-	$Includes => IncludeTypes | IncludeFuncs | IncludeVars | IncludeConst 
+	$Includes => Default | ClassDiagram
+	$Excludes => Imports | Filenames
+	$SkipEmpty => true
 }
-
-*/
\ No newline at end of file
+*/
+package godoc_readme
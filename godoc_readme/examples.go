@@ -0,0 +1,126 @@
+package godoc_readme
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/doc"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/dubbikins/godoc-readme/godoc_readme/template_functions"
+	"golang.org/x/tools/go/packages"
+)
+
+// test_event is an event of the `go test -json` output, see `go doc test2json`
+type test_event struct {
+	Action string
+	Test   string
+	Output string
+}
+
+// run_examples runs the examples of the package with `go test -run '^Example' -json` and returns their results by example func name, i.e. `ExampleReadme_Generate`
+// Only the examples with an output comment are run by `go test`, so the others have no result
+func (readme *Readme) run_examples(pkg *packages.Package, package_doc *doc.Package) (results map[string]*template_functions.ExampleResult, err error) {
+	if len(pkg.GoFiles) == 0 || !has_examples(package_doc) {
+		return
+	}
+	var stderr bytes.Buffer
+	var cmd = exec.Command("go", "test", "-run", "^Example", "-json", ".")
+	cmd.Dir = filepath.Dir(pkg.GoFiles[0])
+	cmd.Env = append(os.Environ(), readme.options.Env...)
+	cmd.Stderr = &stderr
+	var output []byte
+	// A failing example makes `go test` exit with an error, so the error only matters if no example was run
+	output, err = cmd.Output()
+	if results, parse_err := parse_example_results(bytes.NewReader(output)); parse_err != nil || len(results) > 0 {
+		return results, parse_err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: running the examples failed: %w\n%s", pkg.PkgPath, err, strings.TrimSpace(stderr.String()+string(output)))
+	}
+	return nil, nil
+}
+
+// parse_example_results returns the results of the examples in the `go test -json` output
+// The actual output of a failed example is read from the `got:` section `go test` prints for it, a passed example printed its `// Output:` comment
+func parse_example_results(r io.Reader) (results map[string]*template_functions.ExampleResult, err error) {
+	results = map[string]*template_functions.ExampleResult{}
+	var outputs = map[string]*strings.Builder{}
+	var scanner = bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var event test_event
+		if err = json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			// Build errors and other non-JSON lines aren't test events
+			err = nil
+			continue
+		}
+		if !strings.HasPrefix(event.Test, "Example") {
+			continue
+		}
+		switch event.Action {
+		case "output":
+			if outputs[event.Test] == nil {
+				outputs[event.Test] = &strings.Builder{}
+			}
+			outputs[event.Test].WriteString(event.Output)
+		case "pass":
+			results[event.Test] = &template_functions.ExampleResult{Passed: true}
+		case "fail":
+			var result = &template_functions.ExampleResult{}
+			if outputs[event.Test] != nil {
+				result.Output, result.HasOutput = failed_example_output(outputs[event.Test].String())
+			}
+			results[event.Test] = result
+		}
+	}
+	return results, scanner.Err()
+}
+
+// failed_example_output returns the output between the `got:` and `want:` lines that `go test` prints for a failed example
+func failed_example_output(test_output string) (output string, found bool) {
+	var _, got, has_got = strings.Cut(test_output, "\ngot:\n")
+	if !has_got {
+		return
+	}
+	if index := strings.LastIndex(got, "\nwant"); index >= 0 {
+		return got[:index], true
+	}
+	return
+}
+
+// has_examples reports whether the package, or any of its types, funcs or methods, has an example
+func has_examples(package_doc *doc.Package) bool {
+	if package_doc == nil {
+		return false
+	}
+	if len(package_doc.Examples) > 0 {
+		return true
+	}
+	for _, _func := range package_doc.Funcs {
+		if len(_func.Examples) > 0 {
+			return true
+		}
+	}
+	for _, _type := range package_doc.Types {
+		if len(_type.Examples) > 0 {
+			return true
+		}
+		for _, _func := range _type.Funcs {
+			if len(_func.Examples) > 0 {
+				return true
+			}
+		}
+		for _, method := range _type.Methods {
+			if len(method.Examples) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
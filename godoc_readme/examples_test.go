@@ -0,0 +1,34 @@
+package godoc_readme
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseExampleResults(t *testing.T) {
+	var output = strings.Join([]string{
+		`{"Action":"run","Test":"ExamplePass"}`,
+		`{"Action":"output","Test":"ExamplePass","Output":"=== RUN   ExamplePass\n"}`,
+		`{"Action":"output","Test":"ExamplePass","Output":"--- PASS: ExamplePass (0.00s)\n"}`,
+		`{"Action":"pass","Test":"ExamplePass"}`,
+		`{"Action":"run","Test":"ExampleReadme_Generate"}`,
+		`{"Action":"output","Test":"ExampleReadme_Generate","Output":"--- FAIL: ExampleReadme_Generate (0.00s)\n"}`,
+		`{"Action":"output","Test":"ExampleReadme_Generate","Output":"got:\n"}`,
+		`{"Action":"output","Test":"ExampleReadme_Generate","Output":"hello\n"}`,
+		`{"Action":"output","Test":"ExampleReadme_Generate","Output":"world\n"}`,
+		`{"Action":"output","Test":"ExampleReadme_Generate","Output":"want:\n"}`,
+		`{"Action":"output","Test":"ExampleReadme_Generate","Output":"hello\n"}`,
+		`{"Action":"fail","Test":"ExampleReadme_Generate"}`,
+		`{"Action":"fail"}`,
+	}, "\n")
+	results, err := parse_example_results(strings.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results["ExamplePass"] == nil || !results["ExamplePass"].Passed {
+		t.Fatalf("expected ExamplePass to pass, got %+v", results)
+	}
+	if result := results["ExampleReadme_Generate"]; result == nil || result.Passed || !result.HasOutput || result.Output != "hello\nworld" {
+		t.Errorf("expected the actual output of the failed example, got %+v", result)
+	}
+}
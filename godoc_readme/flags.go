@@ -1,40 +1,177 @@
 package godoc_readme
 
-/* RenderFlags can be used to turn on and off rendering of different sections in the README.md file.
+import (
+	"fmt"
+	"strings"
 
-The bitmask values are as follows:
+	"github.com/dubbikins/godoc-readme/godoc_readme/template_functions"
+)
 
-| 1 | 2 | 3 | 4 | 5 | 6 | 7 | 8 | 9 | 10 | ... | 32 |
-|---|---|---|---|---|---|---|---|---|----|-----|----|
-| RenderTypes | RenderFuncs | RenderMethods | RenderVars | RenderConsts | RenderExamples | RenderAlerts | TBD | TBD | TBD | TBD | RenderAll (default) |
-*/
+// The sections of the README, each section is a single bit so they can be combined, i.e. `RenderTypes | RenderFuncs`
+// RenderDefault, all sections except the optional RenderClassDiagram and RenderFields, is rendered by default.
 const (
+	// RenderTypes renders the types with their declarations and docs
 	RenderTypes RenderFlag = 1 << iota
-	RenderFuncs RenderFlag = 2 << iota
+	// RenderFuncs renders the package-level funcs
+	RenderFuncs
+	// RenderMethods renders the methods and method sets of the types
 	RenderMethods
+	// RenderVars renders the package-level vars
 	RenderVars
+	// RenderConsts renders the package-level consts and the typed constants of the types
 	RenderConsts
+	// RenderExamples renders the examples
 	RenderExamples
+	// RenderAlerts renders the `NOTE(...)`, `TIP(...)`, `WARNING(...)` alerts of the doc comments
 	RenderAlerts
-	RenderAll = ^RenderFlag(0)	
+	// RenderNotes renders the notes of the package, i.e. `BUG(...)`
+	RenderNotes
+	// RenderImports renders the import graph of the package
+	RenderImports
+	// RenderFilenames renders the files of the package
+	RenderFilenames
+	// RenderContents renders the table of contents
+	RenderContents
+	// RenderImplementations renders the interfaces of the package with the types that implement them
+	RenderImplementations
+	// RenderClassDiagram renders a mermaid class diagram of the types, it's optional
+	RenderClassDiagram
+	// RenderFields renders the fields of the structs as tables instead of their declarations, it's optional
+	RenderFields
+	// RenderEnvVars renders the environment variables read into the fields of the structs
+	RenderEnvVars
+	// RenderNone renders none of the sections, only the package doc
+	RenderNone RenderFlag = 0
+	// RenderAll renders every section, including the optional ones
+	RenderAll = ^RenderFlag(0)
+	// RenderDefault is every section except the optional ones, which have to be selected by name or with `all`
+	RenderDefault = RenderAll &^ (RenderClassDiagram | RenderFields)
 )
 
-/* RenderFlags can be used to turn on and off rendering of different sections in the README.md file.
-
-The bitmask values are as follows:
-
-| 1 | 2 | 3 | 4 | 5 | 6 | 7 | ... | 32 |
-|---|---|---|---|---|---|---|-----|----|
-| Types | Funcs | TypeMethods | Vars | Consts | Examples | Alerts | TBD | RenderAll (default) |
+/*
+RenderFlag can be used to turn on and off rendering of different sections in the README.md file.
 
+Each section is a bit of the flag, see its constants for their values.
 For example, to render only the types and functions in the README.md file, you would set the 1st and 2nd bits, i.e `0000 0011` or `RenderTypes | RenderFuncs`
 
+The sections can also be selected by name, i.e. `types,funcs`, with the `--render` flag, the `GODOC_README_RENDER` environment variable,
+the `$Includes` and `$Excludes` directives or the `render` template function: `{{ if render "types" }}...{{ end }}`.
+The names are `types`, `funcs`, `methods`, `vars`, `consts`, `examples`, `alerts`, `notes`, `imports`, `filenames`, `contents`, `implementations`, `class_diagram`, `fields`, `env_vars`, `default`, `all` and `none`.
+The `class_diagram` and `fields` sections are optional, they're only rendered if they're selected by name, i.e. `default,class_diagram`, or with `all`.
+The `fields` section renders the exported fields of a struct as a table with their types, tags and docs instead of the struct's declaration.
 */
-type RenderFlag uint32 
+type RenderFlag uint32
+
+// The names of the sections in bit order, used to parse and print a RenderFlag
+var render_flag_names = []struct {
+	name string
+	flag RenderFlag
+}{
+	{"types", RenderTypes},
+	{"funcs", RenderFuncs},
+	{"methods", RenderMethods},
+	{"vars", RenderVars},
+	{"consts", RenderConsts},
+	{"examples", RenderExamples},
+	{"alerts", RenderAlerts},
+	{"notes", RenderNotes},
+	{"imports", RenderImports},
+	{"filenames", RenderFilenames},
+	{"contents", RenderContents},
+	{"implementations", RenderImplementations},
+	{"class_diagram", RenderClassDiagram},
+	{"fields", RenderFields},
+	{"env_vars", RenderEnvVars},
+}
 
 // IsSet returns true if the flag is set in the RenderFlags
 func (f RenderFlag) IsSet(flag RenderFlag) bool{
-	return f & flag == f
+	return f & flag == flag
+}
+
+// ParseRenderFlag parses a `,` or `|` separated list of section names, i.e. `types,funcs` or `Types | Funcs`
+// The names are case-insensitive and may be prefixed with `Render` or `Include`, i.e. `RenderTypes` or `IncludeTypes`
+func ParseRenderFlag(sections string) (flag RenderFlag, err error) {
+	for _, section := range strings.FieldsFunc(sections, func(r rune) bool { return r == ',' || r == '|' }) {
+		var section_flag RenderFlag
+		if section_flag, err = parse_section_name(section); err != nil {
+			return
+		}
+		flag |= section_flag
+	}
+	return
+}
+
+func parse_section_name(section string) (RenderFlag, error) {
+	var name = strings.ToLower(strings.TrimSpace(section))
+	name = strings.TrimPrefix(name, "render")
+	name = strings.TrimPrefix(name, "include")
+	switch name {
+	case "all":
+		return RenderAll, nil
+	case "default":
+		return RenderDefault, nil
+	case "none":
+		return RenderNone, nil
+	}
+	// `ClassDiagram` and `class_diagram` are the same section
+	name = strings.TrimSuffix(strings.ReplaceAll(name, "_", ""), "s")
+	for _, section_name := range render_flag_names {
+		if strings.TrimSuffix(strings.ReplaceAll(section_name.name, "_", ""), "s") == name {
+			return section_name.flag, nil
+		}
+	}
+	return RenderNone, fmt.Errorf("unknown README section %q", section)
 }
 
+// String returns the names of the sections that are set, i.e. `types,funcs`
+func (f RenderFlag) String() string {
+	switch f {
+	case RenderAll:
+		return "all"
+	case RenderDefault:
+		return "default"
+	case RenderNone:
+		return "none"
+	}
+	var names []string
+	for _, section_name := range render_flag_names {
+		if f.IsSet(section_name.flag) {
+			names = append(names, section_name.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
 
+// Set parses the section names into the RenderFlag, it implements the `pflag.Value` interface so it can be used as a CLI flag
+func (f *RenderFlag) Set(sections string) (err error) {
+	*f, err = ParseRenderFlag(sections)
+	return
+}
+
+// Type returns the name of the flag's value type in the CLI help
+func (f *RenderFlag) Type() string {
+	return "sections"
+}
+
+// template_flags returns the sections as the deprecated [template_functions.Flags] of the `flags` template function
+// `ShowAll` is false if none of the sections besides the alerts of the package doc are rendered, like with the `--skip-all` flag
+func (f RenderFlag) template_flags(skip_empty bool) template_functions.Flags {
+	return template_functions.Flags{
+		SkipImports:   !f.IsSet(RenderImports),
+		SkipExamples:  !f.IsSet(RenderExamples),
+		SkipVars:      !f.IsSet(RenderVars),
+		SkipTypes:     !f.IsSet(RenderTypes),
+		SkipFuncs:     !f.IsSet(RenderFuncs),
+		SkipMethods:   !f.IsSet(RenderMethods),
+		SkipFilenames: !f.IsSet(RenderFilenames),
+		SkipConsts:    !f.IsSet(RenderConsts),
+		SkipEmpty:     skip_empty,
+		SkipAll:       f&^RenderAlerts == RenderNone,
+	}
+}
+
+// UnmarshalText parses the section names into the RenderFlag, it's used to read the flag from an environment variable
+func (f *RenderFlag) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
@@ -0,0 +1,85 @@
+package godoc_readme
+
+import (
+	"testing"
+
+	"github.com/dubbikins/godoc-readme/godoc_readme/template_functions"
+)
+
+func TestRenderFlagValues(t *testing.T) {
+	for i, section := range []RenderFlag{RenderTypes, RenderFuncs, RenderMethods, RenderVars, RenderConsts, RenderExamples, RenderAlerts, RenderNotes, RenderImports, RenderFilenames} {
+		if want := RenderFlag(1 << i); section != want {
+			t.Errorf("expected section %d to be %d but got %d", i, want, section)
+		}
+	}
+}
+
+func TestRenderFlagIsSet(t *testing.T) {
+	var flag = RenderTypes | RenderFuncs
+	if !flag.IsSet(RenderTypes) || !flag.IsSet(RenderFuncs) || !flag.IsSet(RenderTypes|RenderFuncs) {
+		t.Errorf("expected types and funcs to be set in %s", flag)
+	}
+	if flag.IsSet(RenderVars) || flag.IsSet(RenderTypes|RenderVars) {
+		t.Errorf("expected vars not to be set in %s", flag)
+	}
+	if !RenderAll.IsSet(RenderMethods) || RenderNone.IsSet(RenderMethods) {
+		t.Errorf("expected methods to be set in all and not in none")
+	}
+}
+
+func TestParseRenderFlag(t *testing.T) {
+	for sections, want := range map[string]RenderFlag{
+		"types,funcs":                 RenderTypes | RenderFuncs,
+		"Types | Funcs":               RenderTypes | RenderFuncs,
+		"RenderMethods,IncludeAlerts": RenderMethods | RenderAlerts,
+		"const,var,example,note":      RenderConsts | RenderVars | RenderExamples | RenderNotes,
+		"all":                         RenderAll,
+		"default,ClassDiagram":        RenderDefault | RenderClassDiagram,
+		"class_diagrams":              RenderClassDiagram,
+		"fields":                      RenderFields,
+		"EnvVars":                     RenderEnvVars,
+		"none":                        RenderNone,
+		"":                            RenderNone,
+	} {
+		have, err := ParseRenderFlag(sections)
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %v", sections, err)
+		}
+		if have != want {
+			t.Errorf("expected %q to parse to %s but got %s", sections, want, have)
+		}
+	}
+	if _, err := ParseRenderFlag("types,everything"); err == nil {
+		t.Errorf("expected an error for an unknown section")
+	}
+	if RenderDefault.IsSet(RenderClassDiagram) || RenderDefault.IsSet(RenderFields) || !RenderDefault.IsSet(RenderImplementations) {
+		t.Errorf("expected the default sections to include implementations and not the class diagram or field tables")
+	}
+	if have, want := RenderDefault.String(), "default"; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+	if have, want := (RenderTypes | RenderFilenames).String(), "types,filenames"; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestRenderFlagTemplateFlags(t *testing.T) {
+	var flags = template_functions.GetFlag((RenderTypes | RenderAlerts).template_flags(true))
+	for flag_name, want := range map[string]bool{
+		"ShowTypes":   true,
+		"ShowFuncs":   false,
+		"ShowImports": false,
+		"ShowEmpty":   true,
+		"ShowAll":     true,
+	} {
+		if have, err := flags(flag_name); err != nil || have != want {
+			t.Errorf("%s: have %t, want %t (err %v)", flag_name, have, want, err)
+		}
+	}
+	if show_all, _ := template_functions.GetFlag(RenderAlerts.template_flags(false))("ShowAll"); show_all {
+		t.Errorf("expected ShowAll to be false when only the alerts of the package doc are rendered")
+	}
+	if _, err := flags("ShowTypo"); err == nil {
+		t.Errorf("expected an error for an unknown flag name")
+	}
+}
@@ -0,0 +1,284 @@
+package godoc_readme
+
+import (
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/types"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/dubbikins/godoc-readme/godoc_readme/template_functions"
+	"golang.org/x/tools/go/packages"
+)
+
+// Index is the data of the module index template, `Index.tmpl`, which lists the packages a README is generated for
+type Index struct {
+	Options ReadmeOptions
+	// Module is the path of the module, i.e. `github.com/dubbikins/godoc-readme`
+	Module string
+	// Groups are the packages grouped by the directory tree of the module, the groups of the module root and its top-level directories sorted by directory
+	Groups []*IndexGroup
+	// Graph is a mermaid graph of the imports between the packages of the module, see [template_functions.ImportGraph]
+	// It includes the packages that aren't matched by the package pattern, but not the `internal` packages unless they're listed
+	Graph string
+}
+
+// IndexGroup is a directory of the module with the packages in it and the groups of its subdirectories
+type IndexGroup struct {
+	// Dir is the directory relative to the module root, i.e. `./godoc_readme/template_functions`, or `.` for the module root
+	Dir string
+	// Title is the heading of the group, the directory or the module path for the module root
+	Title string
+	// Heading is the markdown heading of the group, `##` for the top-level groups and one more `#` for each level below them
+	Heading  string
+	Packages []*IndexPackage
+	// Groups are the nearest subdirectories with packages in them, a directory without a package of its own doesn't have a group
+	Groups []*IndexGroup
+}
+
+// IndexPackage is a package listed in the module index
+type IndexPackage struct {
+	Name       string
+	ImportPath string
+	// Synopsis is the first sentence of the package doc
+	Synopsis string
+	// Readme is the path of the package's README relative to the index
+	Readme string
+	// Types and Funcs are the number of exported types and package-level funcs, including constructors
+	Types int
+	Funcs int
+}
+
+// generate_index renders the module index and checks, writes or confirms it like a package README
+// The index is written to the `Writer` option after the READMEs, or passed to the `WriteFunc` option without a `Pkg`
+func (readme *Readme) generate_index() (index_readme *PackageReadme, err error) {
+	var index *Index
+	if index, err = readme.index(); err != nil || index == nil {
+		return
+	}
+	index_readme = &PackageReadme{Options: *readme.options, index_module: index.Module}
+	if index_readme.file_name, err = readme.index_file(); err != nil {
+		return
+	}
+	var tmpl *template.Template
+	if tmpl, err = parse_index_template(readme.options.TemplateDir, readme.shared_template_functions(index_readme)); err != nil {
+		return
+	}
+	index.walk(func(group *IndexGroup) {
+		for _, index_pkg := range group.Packages {
+			index_pkg.Readme = template_functions.RelativeTo(filepath.Dir(index_readme.file_name))(index_pkg.Readme)
+		}
+	})
+	if err = tmpl.Execute(index_readme, index); err != nil {
+		return
+	}
+	index_readme.content = readme.options.Format(index_readme.Bytes())
+	if readme.options.Check {
+		index_readme.stale, err = readme.check_changes(index_readme)
+		return
+	}
+	if !readme.writes_files() {
+		err = readme.write_output(index_readme)
+		return
+	}
+	if !readme.confirm_changes(index_readme) {
+		index_readme.rejected = true
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(index_readme.file_name), 0755); err != nil {
+		return
+	}
+	err = os.WriteFile(index_readme.file_name, index_readme.content, 0644)
+	return
+}
+
+// index returns the packages of the module index, it returns nil if there aren't any packages
+// The `internal` packages are skipped unless the `IndexInternal` option is set
+func (readme *Readme) index() (index *Index, err error) {
+	var groups = map[string]*IndexGroup{}
+	var index_pkgs []*packages.Package
+	index = &Index{Options: *readme.options}
+	for _, pkg := range readme.Packages {
+		if len(pkg.GoFiles) == 0 || pkg.Module == nil || strings.HasSuffix(pkg.PkgPath, "_test") || strings.HasSuffix(pkg.ID, ".test") {
+			continue
+		}
+		if !readme.options.IndexInternal && is_internal(pkg.PkgPath) {
+			continue
+		}
+		index.Module = pkg.Module.Path
+		index_pkgs = append(index_pkgs, pkg)
+		var index_pkg = &IndexPackage{
+			Name:       pkg.Name,
+			ImportPath: pkg.PkgPath,
+			Synopsis:   package_synopsis(pkg),
+		}
+		index_pkg.Types, index_pkg.Funcs = exported_counts(pkg)
+		var options ReadmeOptions
+		if options, err = readme.package_options(pkg); err != nil {
+			return
+		}
+		if index_pkg.Readme, err = readme.output_file(pkg, &options); err != nil {
+			return
+		}
+		var group = index.group(groups, strings.TrimPrefix(strings.TrimPrefix(pkg.PkgPath, pkg.Module.Path), "/"))
+		group.Packages = append(group.Packages, index_pkg)
+	}
+	if len(index.Groups) == 0 {
+		return nil, nil
+	}
+	var graph_pkgs []*packages.Package
+	for _, pkg := range readme.module_packages() {
+		if readme.options.IndexInternal || !is_internal(pkg.PkgPath) {
+			graph_pkgs = append(graph_pkgs, pkg)
+		}
+	}
+	index.Graph = template_functions.ImportGraph(graph_pkgs, import_graph_options(index_pkgs[0], readme.options))()
+	index.Groups = collapse_groups(index.Groups, "##")
+	sort_groups(index.Groups)
+	return
+}
+
+// group returns the group of the module-relative directory, the groups of its parent directories are added to the index as needed
+// The parent directories without packages are removed by [collapse_groups] once every package has been grouped
+func (index *Index) group(groups map[string]*IndexGroup, rel_dir string) *IndexGroup {
+	if group := groups[rel_dir]; group != nil {
+		return group
+	}
+	var group = &IndexGroup{Dir: "./" + rel_dir, Title: "./" + rel_dir}
+	if rel_dir == "" {
+		group.Dir, group.Title = ".", index.Module
+	}
+	if parent_dir := path.Dir(rel_dir); rel_dir != "" && parent_dir != "." {
+		var parent = index.group(groups, parent_dir)
+		parent.Groups = append(parent.Groups, group)
+	} else {
+		index.Groups = append(index.Groups, group)
+	}
+	groups[rel_dir] = group
+	return group
+}
+
+// collapse_groups replaces the groups without packages by their subdirectories' groups and sets the heading of the groups, *heading* for the groups at this level
+func collapse_groups(groups []*IndexGroup, heading string) (collapsed []*IndexGroup) {
+	var sub_heading = heading
+	if len(sub_heading) < 6 {
+		sub_heading += "#"
+	}
+	for _, group := range groups {
+		if len(group.Packages) == 0 {
+			collapsed = append(collapsed, collapse_groups(group.Groups, heading)...)
+			continue
+		}
+		group.Heading = heading
+		group.Groups = collapse_groups(group.Groups, sub_heading)
+		collapsed = append(collapsed, group)
+	}
+	return
+}
+
+// walk calls *fn* for every group of the index, parents before their subdirectories
+func (index *Index) walk(fn func(group *IndexGroup)) {
+	var walk_groups func(groups []*IndexGroup)
+	walk_groups = func(groups []*IndexGroup) {
+		for _, group := range groups {
+			fn(group)
+			walk_groups(group.Groups)
+		}
+	}
+	walk_groups(index.Groups)
+}
+
+// sort_groups sorts the groups by directory and their packages by import path, recursively
+func sort_groups(groups []*IndexGroup) {
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Dir < groups[j].Dir
+	})
+	for _, group := range groups {
+		sort.Slice(group.Packages, func(i, j int) bool {
+			return group.Packages[i].ImportPath < group.Packages[j].ImportPath
+		})
+		sort_groups(group.Groups)
+	}
+}
+
+// index_file returns the path of the module index, in the module root or the `OutputDir` if it's set
+func (readme *Readme) index_file() (file_name string, err error) {
+	if readme.options.OutputDir != "" {
+		var output_dir string
+		if output_dir, err = filepath.Abs(readme.options.OutputDir); err != nil {
+			return
+		}
+		return filepath.Join(output_dir, readme.options.IndexName), nil
+	}
+	for _, pkg := range readme.Pkgs {
+		if pkg.Module != nil {
+			return filepath.Join(pkg.Module.Dir, readme.options.IndexName), nil
+		}
+	}
+	return "", fmt.Errorf("the module of the packages couldn't be found to write the %s index to", readme.options.IndexName)
+}
+
+// parse_index_template parses the embedded `Index.tmpl` template, or the `Index.tmpl` found in the template directory if one is set
+// The template is parsed with *funcs*, so a custom index template can use the template functions that don't describe a package
+func parse_index_template(template_dir_name string, funcs template.FuncMap) (tmpl *template.Template, err error) {
+	if template_dir_name != "" {
+		var template_dir = os.DirFS(template_dir_name)
+		if _, err = fs.Stat(template_dir, "Index.tmpl"); err == nil {
+			return template.New("Index.tmpl").Funcs(funcs).ParseFS(template_dir, "Index.tmpl")
+		}
+	}
+	return template.New("Index.tmpl").Funcs(funcs).ParseFS(readme_templates, "templates/Index.tmpl")
+}
+
+// is_internal reports whether the import path is an `internal` package or a package nested in one
+func is_internal(import_path string) bool {
+	for _, element := range strings.Split(import_path, "/") {
+		if element == "internal" {
+			return true
+		}
+	}
+	return false
+}
+
+// package_synopsis returns the first sentence of the package doc without its `@godoc-readme{...}` directives
+func package_synopsis(pkg *packages.Package) string {
+	for _, file := range pkg.Syntax {
+		if file.Doc == nil {
+			continue
+		}
+		var _, text, err = ParseDirectives(file.Doc.Text())
+		if err != nil {
+			text = file.Doc.Text()
+		}
+		return new(doc.Package).Synopsis(text)
+	}
+	return ""
+}
+
+// exported_counts returns the number of exported types and package-level funcs of the package
+// The declarations in `_test.go` files, i.e. the `TestXxx` funcs of a test variant of the package, aren't counted
+func exported_counts(pkg *packages.Package) (type_count, func_count int) {
+	if pkg.Types == nil {
+		return
+	}
+	var scope = pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		var object = scope.Lookup(name)
+		if !ast.IsExported(name) || strings.HasSuffix(pkg.Fset.Position(object.Pos()).Filename, "_test.go") {
+			continue
+		}
+		switch object.(type) {
+		case *types.TypeName:
+			type_count++
+		case *types.Func:
+			func_count++
+		}
+	}
+	return
+}
@@ -0,0 +1,151 @@
+package godoc_readme
+
+import (
+	"bytes"
+	"go/ast"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestGenerateIndex(t *testing.T) {
+	var buf bytes.Buffer
+	readme, err := NewReadme(func(ro *ReadmeOptions) {
+		ro.PackageDir = "./..."
+		ro.Index = true
+		ro.Writer = &buf
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = readme.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	_, index, found := strings.Cut(buf.String(), "<!-- godoc-readme: github.com/dubbikins/godoc-readme INDEX.md -->\n")
+	if !found || !strings.Contains(index, "# github.com/dubbikins/godoc-readme\n") {
+		t.Fatalf("expected the index to be written after the READMEs")
+	}
+	if !strings.Contains(buf.String(), "<!-- godoc-readme: github.com/dubbikins/godoc-readme/godoc_readme/template_functions README.md -->\n") {
+		t.Errorf("expected each README to be preceded by a delimiter with its import path")
+	}
+	for _, want := range []string{
+		"## ./godoc_readme\n",
+		"### ./godoc_readme/template_functions\n",
+		"| [template_functions](./godoc_readme/template_functions/README.md) | `github.com/dubbikins/godoc-readme/godoc_readme/template_functions` | Package template_functions |",
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("expected the index to contain %q, have:\n%s", want, index)
+		}
+	}
+}
+
+func TestIndexGroups(t *testing.T) {
+	var module = &packages.Module{Path: "example.com/m", Dir: "/m"}
+	readme := &Readme{options: &ReadmeOptions{OutputName: "README.md"}, Pkgs: map[string]*packages.Package{}, types_pkgs: map[string]*packages.Package{}}
+	var pkgs []*packages.Package
+	for _, rel_dir := range []string{"", "a/util", "b/util", "a/x/y", "a/util/z"} {
+		var pkg_path = strings.TrimSuffix("example.com/m/"+rel_dir, "/")
+		pkgs = append(pkgs, &packages.Package{
+			ID:      pkg_path,
+			Name:    path.Base(pkg_path),
+			PkgPath: pkg_path,
+			GoFiles: []string{filepath.Join("/m", rel_dir, "file.go")},
+			Syntax:  []*ast.File{{}},
+			Module:  module,
+		})
+	}
+	readme.add_packages(pkgs)
+	index, err := readme.index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var have []string
+	index.walk(func(group *IndexGroup) {
+		var names []string
+		for _, index_pkg := range group.Packages {
+			names = append(names, index_pkg.ImportPath)
+		}
+		have = append(have, group.Heading+" "+group.Title+": "+strings.Join(names, ","))
+	})
+	var want = []string{
+		"## example.com/m: example.com/m",
+		"## ./a/util: example.com/m/a/util",
+		"### ./a/util/z: example.com/m/a/util/z",
+		"## ./a/x/y: example.com/m/a/x/y",
+		"## ./b/util: example.com/m/b/util",
+	}
+	if strings.Join(have, "\n") != strings.Join(want, "\n") {
+		t.Errorf("have:\n%s\nwant:\n%s", strings.Join(have, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestGenerateIndexWriteFunc(t *testing.T) {
+	var index []byte
+	readme, err := NewReadme(func(ro *ReadmeOptions) {
+		ro.PackageDir = "./..."
+		ro.Index = true
+		ro.WriteFunc = func(package_readme *PackageReadme, content []byte) error {
+			if package_readme.Pkg == nil {
+				index = content
+			}
+			return nil
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = readme.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(index, []byte("# github.com/dubbikins/godoc-readme\n")) {
+		t.Errorf("expected the index to be passed to the WriteFunc without a package, got %q", index)
+	}
+}
+
+func TestGenerateIndexTemplateFunctions(t *testing.T) {
+	var template_dir = t.TempDir()
+	var index_template = `{{ define "Index.tmpl" }}# {{ title }}{{ range .Groups }}{{ range .Packages }}
+- [{{ .Name }}]({{ .Readme }}) {{ filename .Readme }}{{ end }}{{ end }}
+{{ end }}`
+	if err := os.WriteFile(filepath.Join(template_dir, "Index.tmpl"), []byte(index_template), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var output_dir = filepath.Join(t.TempDir(), "docs")
+	readme, err := NewReadme(func(ro *ReadmeOptions) {
+		ro.Dir = "./testdata/regions"
+		ro.PackageDir = "."
+		ro.Index = true
+		ro.TemplateDir = template_dir
+		ro.OutputDir = output_dir
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = readme.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	index, err := os.ReadFile(filepath.Join(output_dir, "INDEX.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# github.com/dubbikins/godoc-readme\n- [regions](./godoc_readme/testdata/regions/README.md) README.md\n"; string(index) != want {
+		t.Errorf("expected the custom index template to use the template functions, have %q, want %q", index, want)
+	}
+}
+
+func TestIsInternal(t *testing.T) {
+	for import_path, want := range map[string]bool{
+		"example.com/mod/internal":      true,
+		"example.com/mod/internal/util": true,
+		"example.com/mod/internals":     false,
+		"example.com/mod":               false,
+	} {
+		if have := is_internal(import_path); have != want {
+			t.Errorf("%q: have %v, want %v", import_path, have, want)
+		}
+	}
+}
@@ -6,16 +6,20 @@ import (
 	"errors"
 	"fmt"
 	"go/doc"
+	"go/doc/comment"
+	"go/types"
 	"io"
+	"io/fs"
 	"log"
 	"net"
 	"net/http"
 	"os"
-	"path"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -27,45 +31,34 @@ import (
 )
 
 // The readme templates are embedded in the binary so that it can be used as a default template
-// This value can be overridden by providing a template file using the --template flag or the GODOC_README_TEMPLATE_FILE environment variable
+// Any of the partials can be overridden by providing a template directory using the --templates flag or the GODOC_README_TEMPLATE_DIR environment variable
 //
 //go:embed templates/*
 var readme_templates embed.FS
 
 
-//NOTE(Readme): Because of the simpicity of godoc-readme's templating engine, you can add powerful customizations to your documentation like the class diagram that was created using a code block and the [mermaid.js](https://mermaid.js.org/) library that is supported out of the box with Github markdown. (not all features are supported though.)
+//NOTE(Readme): Because of the simpicity of godoc-readme's templating engine, you can add powerful customizations to your documentation like the class diagram of this package, which is generated from its type information with the `class_diagram` template function and rendered with the [mermaid.js](https://mermaid.js.org/) library that is supported out of the box with Github markdown. (not all features are supported though.)
 
 // Readme is a struct that holds the packages, ast and docs of the package
 // And is used to pass data to the readme template
-/*
-```mermaid
-classDiagram
-	class Readme
-	Readme : +map[string]*packages.Package Pkgs
-    Readme : -[]*packages.Package pkgs
-	Readme : -ReadmeOptions options
-	Readme : +Generate() error
-	Readme --> ReadmeOptions
-	Readme --> PackageReadme
-	class ReadmeOptions
-	ReadmeOptions : -string Dir
-	ReadmeOptions : -string DirPattern
-	ReadmeOptions : -string TemplateFile
-	class PackageReadme
-	PackageReadme : +ReadmeOptions Options
-	PackageReadme : +packages.Package Pkg
-	PackageReadme : +doc.Package Doc
-```
-*/
 type Readme struct {
+	// Pkgs are the packages a README is generated for, by import path
 	Pkgs    map[string]*packages.Package
 	TestPkgs    map[string]*packages.Package
+	// types_pkgs are the non-test variants of the packages by import path, their types are the types the other packages import
+	types_pkgs map[string]*packages.Package
 	pkgs    []*packages.Package
 	options *ReadmeOptions
 	readmes []*PackageReadme
 	confirmation_listener net.Listener
 	confirmation_listener_port int
 	confirmation_server *http.Server
+	confirmation_once sync.Once
+	source git_source
+	source_once sync.Once
+	// module_pkgs are the non-test packages of the modules, with their imports, for the import graphs
+	module_pkgs []*packages.Package
+	module_pkgs_once sync.Once
 }
 
 // ReadmeOptions is a struct that holds the options for the Readme struct
@@ -77,9 +70,64 @@ type ReadmeOptions struct {
 	package_load_mode  packages.LoadMode
 	Env  []string `env:"-"`
 	ConfirmUpdates bool
-	Flags template_functions.Flags
+	// Render selects the sections of the README that are rendered, all sections except the optional class diagram and field tables are rendered by default
+	Render RenderFlag `env:"GODOC_README_RENDER" default:"default"`
+	// SkipEmpty skips generating any type, func, var, const, or method that does not have a doc string
+	SkipEmpty bool `env:"GODOC_README_SKIP_EMPTY"`
+	// TemplateDir is a directory containing `*.tmpl` partials that override the embedded partials with the same name
+	// Any partial that isn't found in the directory falls back to the embedded one
+	TemplateDir string `env:"GODOC_README_TEMPLATE_DIR"`
+	// Check renders every README and compares it with the README on disk without writing anything
+	// A unified diff is printed for each stale README and `Generate` returns an [ErrStaleReadme] error
+	Check bool
+	// Writer, if set, receives every rendered README instead of the README file in the package directory
+	// Each README is preceded by a `<!-- godoc-readme: {import path} {file name} -->` line
+	Writer io.Writer `env:"-"`
+	// WriteFunc, if set, is called with every rendered README instead of writing the README file in the package directory
+	// The module index of the `Index` option is passed last, with a nil `Pkg`. It takes precedence over the `Writer` option
+	WriteFunc func(package_readme *PackageReadme, content []byte) error `env:"-"`
+	// OutputName is the file name of the generated READMEs, i.e. `API.md`
+	OutputName string `env:"GODOC_README_OUTPUT_NAME" default:"README.md"`
+	// OutputDir, if set, is the directory the READMEs are written to instead of the package directories
+	// The package paths relative to the module root are mirrored under this directory
+	OutputDir string `env:"GODOC_README_OUTPUT_DIR"`
+	// Title overrides the title of the README, which is the first line of the package doc by default
+	// It's usually set per package with the `$Title` directive
+	Title string `env:"-"`
+	// HeadingOffset is added to the level of the `# Heading`s in doc comments
+	// By default a heading is rendered as `## Heading` in the package doc and as `### Heading` in the doc of a type or func
+	HeadingOffset int `env:"GODOC_README_HEADING_OFFSET"`
+	// Jobs is the number of READMEs that are rendered concurrently, it defaults to the number of CPUs
+	// The READMEs are always written, checked and confirmed one at a time in package order
+	Jobs int `env:"GODOC_README_JOBS"`
+	// Config is the project config file, set by [Config.Apply]
+	// Its `include` and `exclude` patterns select the packages and its `packages` overrides are applied to the matching packages
+	Config *Config `env:"-"`
+	// Index generates a module index listing every package with its synopsis, the link to its README and the number of exported types and funcs
+	// It's rendered with the `Index.tmpl` template and written to the module root, or the `OutputDir` if it's set
+	// A custom `Index.tmpl` in the `TemplateDir` can use the template functions that don't describe a single package, i.e. `title`, `render` or `relative_path`
+	Index bool `env:"GODOC_README_INDEX"`
+	// IndexName is the file name of the module index
+	IndexName string `env:"GODOC_README_INDEX_NAME" default:"INDEX.md"`
+	// IndexInternal lists the `internal` packages in the module index, they're skipped by default
+	IndexInternal bool `env:"GODOC_README_INDEX_INTERNAL"`
+	// SourceURL, if set, links the declarations to the hosted source instead of the relative source file, i.e. `https://github.com/{owner}/{repo}/blob/{ref}/{path}#L{start}-L{end}`
+	// The `{owner}` and `{repo}` are read from the `origin` git remote, or the module path if there's no remote, and `{ref}` is the tag or commit hash of `HEAD` so the links are permalinks
+	// `{path}` is the source file relative to the root of the git repository, `{start}` and `{end}` are the lines of the declaration
+	// The File Names section links to the same URL without its `#L{start}-L{end}` fragment
+	SourceURL string `env:"GODOC_README_SOURCE_URL"`
+	// RunExamples runs the examples of every package with `go test -run '^Example' -json` and renders their actual output
+	// An example whose output doesn't match its `// Output:` comment is marked with a warning alert
+	RunExamples bool `env:"GODOC_README_RUN_EXAMPLES"`
+	// CollapseImports draws the standard library and third-party imports of the import graphs as two grouped nodes instead of a node for each package
+	CollapseImports bool `env:"GODOC_README_COLLAPSE_IMPORTS"`
+	// EnvTags are the struct tags that name the environment variables listed in the environment variables section, `env` and `envconfig` by default
+	EnvTags []string `env:"-"`
 }
 
+// ErrStaleReadme is returned by `Generate` in `Check` mode when any README on disk differs from the generated README
+var ErrStaleReadme = errors.New("stale README")
+
 
 
 
@@ -106,6 +154,7 @@ func NewReadme(opts ...func(*ReadmeOptions)) (readme *Readme, err error) {
 		},
 		Pkgs: map[string]*packages.Package{},
 		TestPkgs: map[string]*packages.Package{},
+		types_pkgs: map[string]*packages.Package{},
 		readmes: []*PackageReadme{},
 	}
 	if err = envy.Unmarshal(readme.options); err != nil {
@@ -114,13 +163,24 @@ func NewReadme(opts ...func(*ReadmeOptions)) (readme *Readme, err error) {
 	for _, opt := range opts {
 		opt(readme.options)
 	}
+	if readme.options.OutputName == "" {
+		readme.options.OutputName = "README.md"
+	}
+	if readme.options.IndexName == "" {
+		readme.options.IndexName = "INDEX.md"
+	}
+	if len(readme.options.EnvTags) == 0 {
+		readme.options.EnvTags = []string{"env", "envconfig"}
+	}
+	if readme.options.Format == nil {
+		readme.options.Format = FormatMarkdown
+	}
+	if readme.options.Check || !readme.writes_files() {
+		// Nothing is written to the package directories so there's nothing to confirm
+		readme.options.ConfirmUpdates = false
+	}
 	
-	if readme.pkgs, err = packages.Load(&packages.Config{
-		Mode:  readme.options.package_load_mode,
-		Dir:   readme.options.Dir,
-		Env:  append(os.Environ(), readme.options.Env...),
-		Tests: true,
-	}, readme.options.PackageDir); err != nil {
+	if readme.pkgs, err = readme.load_packages(readme.options.PackageDir); err != nil {
 		return
 	}
 	if packages.PrintErrors(readme.pkgs) > 0 {
@@ -136,28 +196,45 @@ func NewReadme(opts ...func(*ReadmeOptions)) (readme *Readme, err error) {
 		
 		
 	}
-	for _, pkg := range readme.pkgs {
+	readme.add_packages(readme.pkgs)
+	return
+}
+
+// load_packages loads the packages matching the patterns with the build settings of the options
+func (readme *Readme) load_packages(patterns ...string) ([]*packages.Package, error) {
+	return packages.Load(&packages.Config{
+		Mode:  readme.options.package_load_mode,
+		Dir:   readme.options.Dir,
+		Env:  append(os.Environ(), readme.options.Env...),
+		Tests: true,
+	}, patterns...)
+}
+
+// add_packages registers the loaded packages that a README is generated for, by import path
+func (readme *Readme) add_packages(pkgs []*packages.Package) {
+	for _, pkg := range pkgs {
 		for f_index, file := range pkg.GoFiles {
 			//Strip all of the files that are not go files
 			if !strings.HasSuffix(file, ".go") {
 				pkg.Syntax = append(pkg.Syntax[:f_index], pkg.Syntax[f_index+1:]...)
 			}
 		}
-		if len(pkg.Syntax) == 0 {
+		if len(pkg.Syntax) == 0 || !readme.options.Config.includes(pkg) {
 			continue
 		}
-	
-		if !strings.Contains(pkg.ID, "test") {
-			if _, exists := readme.Pkgs[pkg.Name]; exists {
+		// The test variant of a package, i.e. `p [p.test]`, has an ID that differs from its import path
+		// It's preferred for the README because it has the examples, but the types are read from the non-test variant
+		if pkg.ID == pkg.PkgPath {
+			readme.types_pkgs[pkg.PkgPath] = pkg
+			if _, exists := readme.Pkgs[pkg.PkgPath]; exists {
 				continue
 			}
-			readme.Pkgs[pkg.Name] = pkg	
+			readme.Pkgs[pkg.PkgPath] = pkg	
 		}else {
-			readme.Pkgs[pkg.Name] = pkg
+			readme.Pkgs[pkg.PkgPath] = pkg
 		}
 		
 	}
-	return
 }
 
 // FormatMarkdown applies the following formatting to the markdown:
@@ -183,60 +260,145 @@ type PackageReadme struct {
 	bytes.Buffer
 	rel_file_path string
 	file_name string
-	file *os.File
 	cwd string
 	rejected bool
+	stale bool
+	content []byte
+	// index_module is the module path of the module index, which has no package
+	index_module string
+	link_options template_functions.LinkOptions
+	example_results map[string]*template_functions.ExampleResult
 }
 
 /*
 Generate creates the README.md file for the packages that are registered with a `Readme`
 
-The README is generated in the directory of the package using the template file provided or the default template in none is provided.
+The README is generated in the directory of the package using the embedded templates, with any partials found in the `TemplateDir` option taking precedence.
+See the [ReadmeOptions] for where the READMEs are written and which sections are rendered.
+
 The following template functions available in the template engine are defined in the [`template_functions` package](./template_functions):
 | Function | Description | Example | Output |
 | --- | --- | --- | --- |
-| `example` | Renders a markdown representation of a `[doc.Example]` instance | `{{ example . }}` where `.` is a [doc.Example]| renders [an example like](/#Examples) |
+| `example` | Renders a markdown representation of a `[doc.Example]` instance with its label, doc comment, code and output, whole-file examples are rendered as a complete program | `{{ example . }}` where `.` is a [doc.Example]| renders [an example like](/#Examples) |
 | `code` | Renders the start (or end) of a code block in markdown, optionally specifying the language format of the code block | `{{ code "go" }}fmt.Println("Hello World"){{ code }}` | `` ```go\nfmt.Println("Hello World")\n```\n`` |
 | `fmt` | Renders a formatted string representation of an [ast.Node] | `{{ fmt . }}` | `N/A` |
 | `link` | Renders a markdown link to the location of the [ast.Node] in a package | `{{ link "title" . }}` | `[title](...)` where ... is the relative link to the file ,including line numbers |
+| `file_url` | Returns the link destination of a source file, its `SourceURL` permalink or its path relative to the README | `[{{ filename . }}]({{ file_url . }})` | `[readme.go](./readme.go)` |
 | `alert` | Renders a markdown alert message based on the notes provided in the [doc.Package] | `{{ alert . "title" }}` | renders the alerts with the "title" target |
 | `section` | Renders an indented markdown section header | `{{ section "line 1 text\nline 2 text" 1}}` | `>line 1 text\n>line 2 text` |
-| `doc` | Renders a ***package's*** doc string, including in-line alerts, package ref's, etc | `{{ doc . }}` | `N/A` |
+| `doc` | Renders a doc string with its doc links, i.e. `[Type]`, `[pkg.Func]` or `[Type.Method]`, resolved to markdown links | `{{ doc .Doc }}` | `N/A` |
+| `pkg_doc` | Renders a ***package's*** doc string, including in-line alerts and doc links | `{{ pkg_doc .Doc.Doc }}` | `N/A` |
 | `relative_path` | Replaces the pwd the `.` | `{{ relative_path "/abs/path" }}` where `/abs` is the pwd | returns `./path` |
+| `render` | Reports whether the named sections are rendered, see [RenderFlag] for the section names | `{{ if render "types" }}...{{ end }}` | `true` or `false` |
+| `flags` | Deprecated: reports whether a section is shown, use `render` instead | `{{ if flags "ShowTypes" }}...{{ end }}` | `true` or `false` |
+| `toc` | Returns the table of contents of a [doc.Package], the rendered Types (with their methods), Functions, Constants, Variables and Examples with the anchors of their headings | `{{ range toc .Doc }}- [{{ .Title }}](#{{ .Anchor }}){{ end }}` | `- [Types](#types)` |
+| `method_set` | Returns the method set of a type from its type information: its methods and the methods promoted from embedded types, whether they're on `T` or only `*T`, and the exported interfaces of the module it satisfies | `{{ with $set := method_set .Name }}{{ range $set.Methods }}...{{ end }}{{ end }}` | `N/A` |
+| `class_diagram` | Renders a mermaid class diagram of the exported types of the package with their fields, methods, embedded types and the relations between them, optionally limited to the named types | `{{ class_diagram }}` or `{{ class_diagram "Readme" "ReadmeOptions" }}` | a ```` ```mermaid ```` classDiagram block |
+| `fields` | Returns the exported fields of a struct type with their types, parsed tags and docs, the table view of a struct as opposed to the code view of `gen_decl` | `{{ range fields .Name }}{{ .Name }}: {{ .Type }}{{ end }}` | `N/A` |
+| `enum` | Returns the exported constants of a type with their values evaluated by the type checker, and their hex and binary values if the type is a bitmask | `{{ with enum .Name }}{{ range .Values }}{{ .Name }} = {{ .Value }}{{ end }}{{ end }}` | `N/A` |
+| `env_vars` | Returns the environment variables the structs of the package are filled from, the fields tagged with one of the `EnvTags`, with their type, default, owning struct and doc | `{{ range env_vars }}{{ .Name }}: {{ .Type }}{{ end }}` | `N/A` |
+| `import_graph` | Renders a mermaid graph of the imports of the named packages and the packages of the module that import them, or of the whole module without any import path | `{{ import_graph .Pkg.PkgPath }}` or `{{ import_graph }}` | a ```` ```mermaid ```` graph block |
+| `implementations` | Returns the implementation matrix of the package: its exported interfaces with the types of the module that implement them, and its exported types with the interfaces of the module they satisfy | `{{ with $matrix := implementations }}{{ range $matrix.Interfaces }}...{{ end }}{{ end }}` | `N/A` |
+| `anchor` | Returns the id of a symbol's anchor, its qualified name | `<a href="#{{ anchor "Readme" "Generate" }}">` | `<a href="#Readme.Generate">` |
+| `example_anchor` | Returns the id of a rendered example's anchor, the name of its example func | `<a href="#{{ example_anchor . }}">` | `<a href="#ExampleReadme_Generate">` |
+| `notes` | Returns the notes of a [doc.Package] that aren't github markdown alerts, i.e. `BUG(target): text` | `{{ range $marker, $notes := notes .Doc.Notes }}...{{ end }}` | `N/A` |
 
 Additionally, the following functions are available in the template engine:
 
 - `base`: [filepath.Base] Returns the base name of a file path
 */
 func (readme *Readme) Generate() (err error) {
+	var pkgs []*packages.Package
+	for _, pkg := range readme.Packages {
+		pkgs = append(pkgs, pkg)
+	}
+	return readme.generate_packages(pkgs)
+}
+
+// generate_packages generates the READMEs of the packages and prints the results
+func (readme *Readme) generate_packages(pkgs []*packages.Package) (err error) {
 	if readme.options.ConfirmUpdates {
+		readme.confirmation_once.Do(func() {
+			go func() {
+				err := readme.confirmation_server.Serve(tcpKeepAliveListener{readme.confirmation_listener.(*net.TCPListener)})
+				if !errors.Is(err, http.ErrServerClosed) {
+					log.Fatal(err)
+				}
+			}()
+		})
+	}
+	// The packages of the modules are loaded again by the first import graph, so the packages created while watching are drawn
+	readme.module_pkgs_once = sync.Once{}
+	// The READMEs are rendered by a pool of workers, then written one at a time in package order
+	var pkg_readmes = make([]*PackageReadme, len(pkgs))
+	var render_errs = make([]error, len(pkgs))
+	var workers = make(chan struct{}, readme.jobs())
+	var wg sync.WaitGroup
+	for index, pkg := range pkgs {
+		wg.Add(1)
+		workers <- struct{}{}
 		go func() {
-			err = readme.confirmation_server.Serve(tcpKeepAliveListener{readme.confirmation_listener.(*net.TCPListener)})
-			if !errors.Is(err, http.ErrServerClosed) {
-				log.Fatal(err)
-			}
-
+			defer wg.Done()
+			defer func() { <-workers }()
+			pkg_readmes[index], render_errs[index] = readme.render_pkg_readme(pkg)
 		}()
 	}
-	for _, pkg := range readme.Packages {
-		var pkg_readme *PackageReadme
-		if pkg_readme, err = readme.generate_pkg_readme(pkg,"README.md"); err != nil {
+	wg.Wait()
+	readme.readmes = readme.readmes[:0]
+	for index, pkg_readme := range pkg_readmes {
+		if err = render_errs[index]; err != nil {
+			return
+		}
+		if err = readme.write_pkg_readme(pkg_readme); err != nil {
 			return
 		}
 		readme.readmes = append(readme.readmes, pkg_readme)
 	}
+	if readme.options.Index {
+		var index_readme *PackageReadme
+		if index_readme, err = readme.generate_index(); err != nil {
+			return
+		}
+		if index_readme != nil {
+			readme.readmes = append(readme.readmes, index_readme)
+		}
+	}
+	if !readme.writes_files() && !readme.options.Check {
+		// The output belongs to the writer, so don't mix the results in with it
+		return
+	}
 	fmt.Println("Results:")
+	var stale_count int
 	for _readme := range readme.READMES {
-		if !_readme.rejected  || !readme.options.ConfirmUpdates {
+		if _readme.stale {
+			stale_count++
+			fmt.Printf("\t- %q is stale \u274c\n", _readme.file_name)
+		} else if !_readme.rejected  || !readme.options.ConfirmUpdates {
 			fmt.Printf("\t- %q \u2705\n", _readme.file_name)
 		}else {
 			fmt.Printf("\t- %q \u274c\n", _readme.file_name)
 		}
 	
 	}
+	if stale_count > 0 {
+		err = fmt.Errorf("%w: %d of %d README files are out of date, run godoc-readme to regenerate them", ErrStaleReadme, stale_count, len(readme.readmes))
+	}
 	return
 }
 
+// jobs returns the number of READMEs that are rendered concurrently
+func (readme *Readme) jobs() int {
+	if readme.options.Jobs < 1 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return readme.options.Jobs
+}
+
+// writes_files returns false if the READMEs are written to the `Writer` or `WriteFunc` options instead of the package directories
+func (readme *Readme) writes_files() bool {
+	return readme.options.Writer == nil && readme.options.WriteFunc == nil
+}
+
 func (readme *Readme) READMES(yield func(*PackageReadme) bool) {
 	for _, _readme := range readme.readmes {
 		if !yield(_readme) {
@@ -251,6 +413,9 @@ func (readme *Readme) Packages(yield func(string, *packages.Package) bool) {
 		sorted_pkg_keys = append(sorted_pkg_keys, pkg)
 	}
 	sort.Slice(sorted_pkg_keys, func(i, j int) bool {
+		if sorted_pkg_keys[i].Name == sorted_pkg_keys[j].Name {
+			return sorted_pkg_keys[i].PkgPath < sorted_pkg_keys[j].PkgPath
+		}
 		return strings.Compare(sorted_pkg_keys[i].Name, sorted_pkg_keys[j].Name)  == -1
 	})
 	for _, pkg := range sorted_pkg_keys {
@@ -259,32 +424,249 @@ func (readme *Readme) Packages(yield func(string, *packages.Package) bool) {
 		}
 	}
 }
-func (readme *Readme) template_functions (package_readme *PackageReadme) template.FuncMap {
+
+// shared_template_functions returns the template functions that don't describe a package, the module index template is parsed with them
+// The package templates are parsed with the same functions, see [Readme.template_functions]
+func (readme *Readme) shared_template_functions(package_readme *PackageReadme) template.FuncMap {
+	var title = package_readme.Options.Title
+	if title == "" {
+		title = package_readme.index_module
+	}
+	var pkg_doc_options = template_functions.DocOptions{HeadingLevel: 2 + package_readme.Options.HeadingOffset}
+	var doc_options = pkg_doc_options
+	doc_options.HeadingLevel++
 	return template.FuncMap{
-		"example":       template_functions.ExampleCode(package_readme.Pkg),
-		"skip_empty":    template_functions.SkipEmpty(readme.options.Flags.SkipEmpty),
+		"skip_empty":    template_functions.SkipEmpty(package_readme.Options.SkipEmpty),
 		"filtered_funcs":    template_functions.FilteredFuncs(template_functions.MethodsOptions{
-			SkipEmpty: readme.options.Flags.SkipEmpty,
+			SkipEmpty: package_readme.Options.SkipEmpty,
+		}),
+		"notes":         template_functions.Notes,
+		"doc":           template_functions.DocStringWith(doc_options),
+		"section":       template_functions.Section,
+		"pkg_doc":       template_functions.PackageDocStringWith(pkg_doc_options),
+		"relative_path": template_functions.RelativeTo(filepath.Dir(package_readme.file_name)),
+		"title":         func() string { return title },
+		"render":        package_readme.render,
+		// Deprecated: `flags` is kept for the templates written before the `render` function, i.e. `{{ if flags "ShowTypes" }}`
+		"flags":         template_functions.GetFlag(package_readme.Options.Render.template_flags(package_readme.Options.SkipEmpty)),
+		"toc":           template_functions.Toc(template_functions.TocOptions{
+			Types:     package_readme.Options.Render.IsSet(RenderTypes),
+			Methods:   package_readme.Options.Render.IsSet(RenderMethods),
+			Funcs:     package_readme.Options.Render.IsSet(RenderFuncs),
+			Consts:    package_readme.Options.Render.IsSet(RenderConsts),
+			Vars:      package_readme.Options.Render.IsSet(RenderVars),
+			Examples:  package_readme.Options.Render.IsSet(RenderExamples),
+			SkipEmpty: package_readme.Options.SkipEmpty,
+		}),
+		"filename":          filepath.Base,
+		"anchor":            template_functions.Anchor,
+		"example_anchor":    template_functions.ExampleAnchor,
+	}
+}
+
+// template_functions returns the functions of the package templates, the [Readme.shared_template_functions] and the functions that describe the package
+func (readme *Readme) template_functions (package_readme *PackageReadme) (funcs template.FuncMap) {
+	var title = template_functions.Title(package_readme.Pkg, package_readme.Doc)
+	if package_readme.Options.Title != "" {
+		title = func() string { return package_readme.Options.Title }
+	}
+	var alert = template_functions.Alert(package_readme.Pkg, package_readme.Doc.Notes)
+	var module_types = readme.module_types()
+	var module_interfaces = template_functions.Interfaces(module_types...)
+	if !package_readme.Options.Render.IsSet(RenderAlerts) {
+		alert = func(string) string { return "" }
+	}
+	// A `# Heading` in the package doc is nested under the README title and a heading in a symbol's doc under the symbol's heading
+	var pkg_doc_options = template_functions.DocOptions{
+		Parser:       package_readme.Doc.Parser(),
+		LinkURL:      readme.doc_link_url(package_readme),
+		HeadingLevel: 2 + package_readme.Options.HeadingOffset,
+	}
+	var doc_options = pkg_doc_options
+	doc_options.HeadingLevel++
+	funcs = readme.shared_template_functions(package_readme)
+	for name, fn := range (template.FuncMap{
+		"example":       template_functions.ExampleCodeWith(package_readme.Pkg, template_functions.ExampleOptions{
+			Results: package_readme.example_results,
+			Doc:     doc_options,
 		}),
 		"code":          template_functions.CodeBlock(package_readme.Pkg),
 		"fmt":           template_functions.FormatNode(package_readme.Pkg),
-		"link":          template_functions.Link(package_readme.Pkg),
-		"alert":         template_functions.Alert(package_readme.Pkg, package_readme.Doc.Notes),
-		"doc":           template_functions.DocString,
+		"link":          template_functions.LinkWith(package_readme.Pkg, package_readme.link_options),
+		"file_url":      template_functions.FileURL(package_readme.link_options),
+		"alert":         alert,
+		"doc":           template_functions.DocStringWith(doc_options),
 		"gen_decl": 	 template_functions.GenDeclaration(package_readme.Pkg),
 		"spec_decl": 	 template_functions.SpecDeclaration(package_readme.Pkg),
 		"fn_decl": 		 template_functions.FuncDeclaration(package_readme.Pkg),
 		"decl":          template_functions.Declaration(package_readme.Pkg),
-		"section":       template_functions.Section,
-		"pkg_doc":       template_functions.PackageDocString,
-		"relative_path": template_functions.RelativeFilename,
-		"title":         template_functions.Title(package_readme.Pkg, package_readme.Doc),
-		"flags":         template_functions.GetFlag(readme.options.Flags),
-		"filename":          filepath.Base,
+		"pkg_doc":       template_functions.PackageDocStringWith(pkg_doc_options),
+		"title":         title,
+		"method_set":        template_functions.MethodSets(readme.package_types(package_readme.Pkg), template_functions.MethodSetOptions{
+			Interfaces: module_interfaces,
+			LinkURL:    readme.doc_link_url(package_readme),
+		}),
+		"class_diagram":     template_functions.ClassDiagram(readme.package_types(package_readme.Pkg)),
+		"fields":            template_functions.Fields(package_readme.Pkg, template_functions.FieldOptions{
+			LinkURL: readme.doc_link_url(package_readme),
+			Doc:     doc_options,
+		}),
+		"enum":              template_functions.Enums(package_readme.Pkg, template_functions.EnumOptions{Doc: doc_options}),
+		"env_vars":          template_functions.EnvVars(package_readme.Pkg, template_functions.EnvVarOptions{
+			Tags:    package_readme.Options.EnvTags,
+			LinkURL: readme.doc_link_url(package_readme),
+			Doc:     doc_options,
+		}),
+		"import_graph":      template_functions.ImportGraph(readme.module_packages(), import_graph_options(package_readme.Pkg, &package_readme.Options)),
+		"implementations":   template_functions.Implementations(readme.package_types(package_readme.Pkg), template_functions.ImplementationsOptions{
+			Interfaces: module_interfaces,
+			Types:      template_functions.ConcreteTypes(module_types...),
+			LinkURL:    readme.doc_link_url(package_readme),
+		}),
+	}) {
+		funcs[name] = fn
+	}
+	return
+}
+
+// doc_link_url returns the URL of the doc links in the package's README
+// Links to the package itself are in-page anchors, links to the other packages of the module are relative links to their READMEs
+// and links to any other package, i.e. the standard library, point to pkg.go.dev
+func (readme *Readme) doc_link_url(package_readme *PackageReadme) func(link *comment.DocLink) string {
+	return func(link *comment.DocLink) string {
+		if link.ImportPath == "" || link.ImportPath == package_readme.Pkg.PkgPath {
+			if anchor := template_functions.DocLinkAnchor(package_readme.Pkg.Types, link); anchor != "" {
+				return "#" + anchor
+			}
+			return ""
+		}
+		linked_pkg, file_name, found := readme.module_readme_file(package_readme.Pkg, link.ImportPath)
+		if !found {
+			return template_functions.DocLinkPkgGoDev(link)
+		}
+		var url = template_functions.RelativeTo(filepath.Dir(package_readme.file_name))(file_name)
+		if anchor := template_functions.DocLinkAnchor(linked_pkg.Types, link); anchor != "" {
+			url += "#" + anchor
+		}
+		return url
 	}
 }
 
-func (readme *Readme) generate_pkg_readme(pkg *packages.Package, filename string) (package_readme *PackageReadme, err error) {
+// module_types returns the type information of the loaded packages of the module, without the external test packages
+func (readme *Readme) module_types() (pkgs []*types.Package) {
+	for _, pkg := range readme.Packages {
+		if !strings.HasSuffix(pkg.PkgPath, "_test") {
+			pkgs = append(pkgs, readme.package_types(pkg))
+		}
+	}
+	return
+}
+
+// package_types returns the type information of the non-test variant of the package
+// The types of a test variant are checked again with the `_test.go` files, so they aren't identical to the types the other packages import
+// and a type of the module wouldn't implement an interface whose methods use them
+func (readme *Readme) package_types(pkg *packages.Package) *types.Package {
+	if types_pkg, found := readme.types_pkgs[pkg.PkgPath]; found && types_pkg.Types != nil {
+		return types_pkg.Types
+	}
+	return pkg.Types
+}
+
+// module_packages returns the non-test packages of the modules of the loaded packages with their imports
+// Every package of a module is loaded, not only the ones matched by the package pattern, so the import graphs show all of the importers of a package.
+// If the modules can't be loaded, the non-test variants of the loaded packages are returned
+func (readme *Readme) module_packages() []*packages.Package {
+	readme.module_pkgs_once.Do(func() {
+		var module_dirs = map[string]bool{}
+		for _, pkg := range readme.Packages {
+			if pkg.Module != nil && pkg.Module.Dir != "" {
+				module_dirs[pkg.Module.Dir] = true
+			}
+		}
+		readme.module_pkgs = nil
+		for module_dir := range module_dirs {
+			var pkgs, err = packages.Load(&packages.Config{
+				Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedModule,
+				Dir:  module_dir,
+				Env:  append(os.Environ(), readme.options.Env...),
+			}, "./...")
+			if err != nil {
+				readme.module_pkgs = nil
+				break
+			}
+			readme.module_pkgs = append(readme.module_pkgs, pkgs...)
+		}
+		if readme.module_pkgs != nil {
+			return
+		}
+		for _, pkg := range readme.Packages {
+			if types_pkg, found := readme.types_pkgs[pkg.PkgPath]; found {
+				readme.module_pkgs = append(readme.module_pkgs, types_pkg)
+			}
+		}
+	})
+	return readme.module_pkgs
+}
+
+// import_graph_options returns the options of the import graphs of the module that *pkg* is in
+func import_graph_options(pkg *packages.Package, options *ReadmeOptions) (graph_options template_functions.ImportGraphOptions) {
+	graph_options.Collapse = options.CollapseImports
+	if pkg.Module != nil {
+		graph_options.Module = pkg.Module.Path
+	}
+	return
+}
+
+// module_readme_file returns the package with the import path and the path of its README, if the package is in the same module as *from*
+func (readme *Readme) module_readme_file(from *packages.Package, import_path string) (pkg *packages.Package, file_name string, found bool) {
+	for _, loaded_pkg := range readme.Pkgs {
+		if loaded_pkg.PkgPath == import_path {
+			pkg = loaded_pkg
+			break
+		}
+	}
+	if pkg == nil {
+		var module = from.Module
+		if module == nil || (import_path != module.Path && !strings.HasPrefix(import_path, module.Path+"/")) {
+			return
+		}
+		// The package isn't loaded, so its README is where it would be generated with the default options
+		var pkg_dir = filepath.Join(module.Dir, filepath.FromSlash(strings.TrimPrefix(import_path, module.Path)))
+		pkg = &packages.Package{PkgPath: import_path, GoFiles: []string{filepath.Join(pkg_dir, "doc.go")}, Module: module}
+	}
+	options, err := readme.package_options(pkg)
+	if err != nil {
+		return
+	}
+	if file_name, err = readme.output_file(pkg, &options); err != nil {
+		return
+	}
+	return pkg, file_name, true
+}
+
+// package_options returns the options of a package with the config file overrides and the package's own directives applied
+func (readme *Readme) package_options(pkg *packages.Package) (options ReadmeOptions, err error) {
+	options = *readme.options
+	if err = readme.options.Config.apply_package(pkg, &options); err != nil {
+		return
+	}
+	for _, file := range pkg.Syntax {
+		if file.Doc == nil {
+			continue
+		}
+		var directives *Directives
+		if directives, _, err = ParseDirectives(file.Doc.Text()); err != nil || directives == nil {
+			continue
+		}
+		err = directives.apply(&options)
+		return
+	}
+	return options, nil
+}
+
+// render_pkg_readme renders the README of the package without writing it
+// It only reads the shared state of the Readme so it's safe to render several packages concurrently
+func (readme *Readme) render_pkg_readme(pkg *packages.Package) (package_readme *PackageReadme, err error) {
 		package_readme = &PackageReadme{
 			Pkg:     pkg,
 			Options: *readme.options,
@@ -295,37 +677,153 @@ func (readme *Readme) generate_pkg_readme(pkg *packages.Package, filename string
 		if len(pkg.GoFiles) == 0 {
 			return
 		}
-		var readme_file_path = filepath.Dir(pkg.GoFiles[0])
-		package_readme.file_name = path.Join(readme_file_path,filename )
+		if err = readme.options.Config.apply_package(pkg, &package_readme.Options); err != nil {
+			return nil, fmt.Errorf("%s: %w", pkg.PkgPath, err)
+		}
+		var directives *Directives
+		if directives, package_readme.Doc.Doc, err = ParseDirectives(package_readme.Doc.Doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", pkg.PkgPath, err)
+		}
+		if directives != nil {
+			if err = directives.apply(&package_readme.Options); err != nil {
+				return nil, fmt.Errorf("%s: %w", pkg.PkgPath, err)
+			}
+		}
+		if package_readme.file_name, err = readme.output_file(pkg, &package_readme.Options); err != nil {
+			return
+		}
+		if package_readme.link_options, err = readme.link_options(package_readme); err != nil {
+			return nil, fmt.Errorf("%s: %w", pkg.PkgPath, err)
+		}
+		if package_readme.Options.RunExamples {
+			if package_readme.example_results, err = readme.run_examples(pkg, package_readme.Doc); err != nil {
+				return
+			}
+		}
 		var tmpl *template.Template
-		if tmpl, err = template.New("README.tmpl").Funcs(readme.template_functions(package_readme)).ParseFS(readme_templates, "templates/*.tmpl"); err != nil {
+		if tmpl, err = readme.parse_templates(package_readme.Options.TemplateDir, readme.template_functions(package_readme)); err != nil {
 			return
-		}	
+		}
 		if err = tmpl.Execute(package_readme, package_readme); err != nil {
 			return
 		}
-		if readme.options.Format == nil {
-			readme.options.Format = FormatMarkdown
+		package_readme.content, err = readme.merge_existing(tmpl, package_readme)
+		return
+}
+
+// write_pkg_readme checks, writes or confirms the rendered README of the package
+// It's called for one package at a time, in package order, so the output and the confirmation prompts aren't interleaved
+func (readme *Readme) write_pkg_readme(package_readme *PackageReadme) (err error) {
+		if len(package_readme.Pkg.GoFiles) == 0 {
+			return
+		}
+		if readme.options.Check {
+			package_readme.stale, err = readme.check_changes(package_readme)
+			return
+		}
+		if !readme.writes_files() {
+			err = readme.write_output(package_readme)
+			return
 		}
 
 		if !readme.confirm_changes(package_readme) {
 			package_readme.rejected = true
 			return
 		}
-		if package_readme.file, err = os.Create(package_readme.file_name); err != nil { 
+		if err = os.MkdirAll(filepath.Dir(package_readme.file_name), 0755); err != nil {
 			return
 		}
-		if _, err = package_readme.file.Write(readme.options.Format(package_readme.Bytes())); err != nil {
+		if err = os.WriteFile(package_readme.file_name, package_readme.content, 0644); err != nil {
 			return
 		}
 		if  package_readme.cwd , err = os.Getwd(); err != nil {
 			return
 		}
 
-		package_readme.rel_file_path = filepath.Join(strings.Replace( package_readme.Pkg.PkgPath, package_readme.Pkg.Module.Path, "./", 1), filename)
+		package_readme.rel_file_path = filepath.Join(strings.Replace( package_readme.Pkg.PkgPath, package_readme.Pkg.Module.Path, "./", 1), package_readme.Options.OutputName)
 		return 
 }
 
+// render returns true if all of the named sections are rendered for the package, i.e. `{{ if render "types" }}`
+// It returns an error for an unknown section name
+func (package_readme *PackageReadme) render(sections ...string) (bool, error) {
+	for _, section := range sections {
+		flag, err := ParseRenderFlag(section)
+		if err != nil {
+			return false, err
+		}
+		if !package_readme.Options.Render.IsSet(flag) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// link_options returns the options of the package's source links
+// The hosted repository of the `SourceURL` option is resolved from git the first time it's needed
+func (readme *Readme) link_options(package_readme *PackageReadme) (options template_functions.LinkOptions, err error) {
+	options.Dir = filepath.Dir(package_readme.file_name)
+	if package_readme.Options.SourceURL == "" {
+		return
+	}
+	var module = package_readme.Pkg.Module
+	var module_dir, module_path = filepath.Dir(package_readme.Pkg.GoFiles[0]), ""
+	if module != nil {
+		module_dir, module_path = module.Dir, module.Path
+	}
+	readme.source_once.Do(func() {
+		readme.source = resolve_git_source(module_dir, module_path)
+	})
+	if err = readme.source.validate(package_readme.Options.SourceURL); err != nil {
+		return
+	}
+	options.SourceURL = package_readme.Options.SourceURL
+	options.RepoDir, options.Owner, options.Repo, options.Ref = readme.source.root, readme.source.owner, readme.source.repo, readme.source.ref
+	return
+}
+
+// output_file returns the path of the README file for the package
+// The README is written to the package directory unless the `OutputDir` option is set, in which case
+// the package's directory relative to the module root is mirrored under the output directory
+func (readme *Readme) output_file(pkg *packages.Package, options *ReadmeOptions) (file_name string, err error) {
+	var pkg_dir = filepath.Dir(pkg.GoFiles[0])
+	if options.OutputDir == "" {
+		return filepath.Join(pkg_dir, options.OutputName), nil
+	}
+	var module_dir = pkg_dir
+	if pkg.Module != nil {
+		module_dir = pkg.Module.Dir
+	}
+	var rel_pkg_dir, output_dir string
+	if rel_pkg_dir, err = filepath.Rel(module_dir, pkg_dir); err != nil {
+		return
+	}
+	if output_dir, err = filepath.Abs(options.OutputDir); err != nil {
+		return
+	}
+	return filepath.Join(output_dir, rel_pkg_dir, options.OutputName), nil
+}
+
+// parse_templates parses the embedded templates and then the `*.tmpl` partials found in the template directory, if one is set
+// A partial in the template directory replaces the embedded partial with the same name, so only the partials that need restyling have to be provided
+func (readme *Readme) parse_templates(template_dir_name string, funcs template.FuncMap) (tmpl *template.Template, err error) {
+	if tmpl, err = template.New("README.tmpl").Funcs(funcs).ParseFS(readme_templates, "templates/*.tmpl"); err != nil {
+		return
+	}
+	if template_dir_name == "" {
+		return
+	}
+	var template_dir = os.DirFS(template_dir_name)
+	var partials []string
+	if partials, err = fs.Glob(template_dir, "*.tmpl"); err != nil {
+		return
+	}
+	if len(partials) == 0 {
+		return nil, fmt.Errorf("no *.tmpl partials found in template directory %q", template_dir_name)
+	}
+	return tmpl.ParseFS(template_dir, partials...)
+}
+
 type tcpKeepAliveListener struct {
 	*net.TCPListener
 }
@@ -340,6 +838,80 @@ func (ln tcpKeepAliveListener) Accept() (c net.Conn, err error) {
 	return tc, nil
 }
 
+// merge_existing returns the formatted README for the package
+// If the existing README contains godoc-readme region markers, only the regions are replaced:
+// a named region is filled with the `region:<name>` template and an unnamed region with the `region:body` template,
+// the README without its title and generated banner
+func (readme *Readme) merge_existing(tmpl *template.Template, package_readme *PackageReadme) (content []byte, err error) {
+	content = readme.options.Format(package_readme.Bytes())
+	var existing_data []byte
+	if existing_data, err = os.ReadFile(package_readme.file_name); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+		return
+	}
+	var merged []byte
+	var found bool
+	if merged, found, err = merge_regions(existing_data, func(name string) ([]byte, error) {
+		if name == "" {
+			name = "body"
+		}
+		var region_tmpl = tmpl.Lookup("region:" + name)
+		if region_tmpl == nil {
+			return nil, fmt.Errorf("%s: no template is defined for the godoc-readme region %q", package_readme.file_name, name)
+		}
+		var buf = bytes.NewBuffer(nil)
+		if err := region_tmpl.Execute(buf, package_readme); err != nil {
+			return nil, err
+		}
+		return readme.options.Format(buf.Bytes()), nil
+	}); err != nil || !found {
+		return
+	}
+	return merged, nil
+}
+
+// write_output passes the rendered README to the `WriteFunc` option or writes it to the `Writer` option
+func (readme *Readme) write_output(package_readme *PackageReadme) (err error) {
+	if readme.options.WriteFunc != nil {
+		return readme.options.WriteFunc(package_readme, package_readme.content)
+	}
+	if _, err = io.WriteString(readme.options.Writer, package_readme.output_delimiter()); err != nil {
+		return
+	}
+	_, err = readme.options.Writer.Write(package_readme.content)
+	return
+}
+
+// output_delimiter returns the line that's written to the `Writer` option before the README, i.e. `<!-- godoc-readme: github.com/dubbikins/godoc-readme/cmd README.md -->`
+// It names the package, or the module for the module index, and the file name so the output can be split into the READMEs again
+func (package_readme *PackageReadme) output_delimiter() string {
+	var import_path = package_readme.index_module
+	if package_readme.Pkg != nil {
+		import_path = package_readme.Pkg.PkgPath
+	}
+	return fmt.Sprintf("<!-- godoc-readme: %s %s -->\n", import_path, filepath.Base(package_readme.file_name))
+}
+
+// check_changes compares the generated README with the README on disk and prints a unified diff if they differ
+// A README that doesn't exist yet is compared against an empty file
+func (readme *Readme) check_changes(package_readme *PackageReadme) (stale bool, err error) {
+	var existing_data []byte
+	if existing_data, err = os.ReadFile(package_readme.file_name); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+		err = nil
+	}
+	var diff = unified_diff(package_readme.file_name, package_readme.file_name+" (generated)", string(existing_data), string(package_readme.content))
+	if diff == "" {
+		return
+	}
+	fmt.Print(diff)
+	return true, nil
+}
+
 func (readme *Readme) confirm_changes(package_readme *PackageReadme) (confirm bool) {
 	var existing_file *os.File
 	if !readme.options.ConfirmUpdates {
@@ -356,7 +928,7 @@ func (readme *Readme) confirm_changes(package_readme *PackageReadme) (confirm bo
 			if existing_data, err = io.ReadAll(existing_file); err != nil {
 				return
 			}
-			diffs := dmp.DiffMain(string(existing_data), string(readme.options.Format(package_readme.Bytes())), false)
+			diffs := dmp.DiffMain(string(existing_data), string(package_readme.content), false)
 			// var differ *bytes.Buffer
 			confirmation_response := make(chan bool, 1)
 			readme.confirmation_server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1,9 +1,15 @@
 package godoc_readme
 
 import (
+	"bytes"
 	"fmt"
+	"go/doc"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"golang.org/x/tools/go/packages"
 )
 
 
@@ -77,3 +83,139 @@ func ExampleReadme_Generate() {
 
 
 
+
+func TestParseTemplatesFallback(t *testing.T) {
+	template_dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(template_dir, ".Func.tmpl"), []byte(`{{ define ".Func.tmpl" }}custom func {{ .Name }}{{ end }}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	readme := &Readme{options: &ReadmeOptions{}}
+	tmpl, err := readme.parse_templates(template_dir, readme.template_functions(&PackageReadme{Pkg: &packages.Package{}, Doc: &doc.Package{}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = tmpl.ExecuteTemplate(&buf, ".Func.tmpl", &doc.Func{Name: "NewReadme"}); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := buf.String(), "custom func NewReadme"; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+	for _, partial := range []string{"README.tmpl", ".Type.tmpl", ".Type.Methods.tmpl", ".Funcs.tmpl"} {
+		if tmpl.Lookup(partial) == nil {
+			t.Errorf("expected the embedded %q partial to be used as a fallback", partial)
+		}
+	}
+}
+
+func TestParseTemplatesEmptyDir(t *testing.T) {
+	readme := &Readme{options: &ReadmeOptions{}}
+	if _, err := readme.parse_templates(t.TempDir(), readme.template_functions(&PackageReadme{Pkg: &packages.Package{}, Doc: &doc.Package{}})); err == nil {
+		t.Errorf("expected an error for a template directory without partials")
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	if have := unified_diff("a", "b", "same\n", "same\n"); have != "" {
+		t.Errorf("expected no diff for equal text but got %q", have)
+	}
+	have := unified_diff("README.md", "README.md (generated)", "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n", "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n11\n12\n13\n")
+	want := `--- README.md
++++ README.md (generated)
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`
+	if have != want {
+		t.Errorf("have:\n%s\nwant:\n%s", have, want)
+	}
+	have = unified_diff("a", "b", "", "new\n")
+	want = "--- a\n+++ b\n@@ -0,0 +1,1 @@\n+new\n"
+	if have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestGenerateWriteFunc(t *testing.T) {
+	var written = map[string][]byte{}
+	readme, err := NewReadme(func(ro *ReadmeOptions) {
+		ro.PackageDir = "."
+		ro.WriteFunc = func(package_readme *PackageReadme, content []byte) error {
+			written[package_readme.Pkg.PkgPath] = content
+			return nil
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = readme.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	content, found := written["github.com/dubbikins/godoc-readme/godoc_readme"]
+	if !found {
+		t.Fatalf("expected the README for the godoc_readme package to be passed to the WriteFunc, got %d READMEs", len(written))
+	}
+	if !bytes.Contains(content, []byte("# Package `godoc_readme`")) {
+		t.Errorf("expected the rendered README to contain the package title")
+	}
+}
+
+func TestGenerateJobsOrder(t *testing.T) {
+	generate := func(jobs int) (written []string) {
+		readme, err := NewReadme(func(ro *ReadmeOptions) {
+			ro.PackageDir = "./..."
+			ro.Jobs = jobs
+			ro.WriteFunc = func(package_readme *PackageReadme, content []byte) error {
+				written = append(written, package_readme.Pkg.PkgPath)
+				return nil
+			}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = readme.Generate(); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	serial, parallel := generate(1), generate(8)
+	if len(serial) < 2 || strings.Join(serial, ",") != strings.Join(parallel, ",") {
+		t.Errorf("expected the READMEs to be written in the same order, have %q and %q", serial, parallel)
+	}
+}
+
+func TestGenerateCrossPackageImplementations(t *testing.T) {
+	var written = map[string]string{}
+	readme, err := NewReadme(func(ro *ReadmeOptions) {
+		ro.Dir = "./testdata/implementations"
+		ro.PackageDir = "./..."
+		ro.WriteFunc = func(package_readme *PackageReadme, content []byte) error {
+			written[package_readme.Pkg.Name] = string(content)
+			return nil
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = readme.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	// The plugin package has a test file, so its README is rendered from its test variant
+	if have := written["plugin"]; !strings.Contains(have, "| [Plugin](#Plugin) | [`foo.Foo`](../foo/README.md#Foo) |") {
+		t.Errorf("expected Foo to be listed as an implementation of Plugin, got:\n%s", have)
+	}
+	if have := written["foo"]; !strings.Contains(have, "- [plugin.Plugin](../plugin/README.md#Plugin)") {
+		t.Errorf("expected the method set of Foo to implement plugin.Plugin, got:\n%s", have)
+	}
+}
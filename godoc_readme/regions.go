@@ -0,0 +1,102 @@
+package godoc_readme
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+)
+
+// The markers that delimit a generated region in an existing README, i.e. `<!-- godoc-readme:start -->` and `<!-- godoc-readme:end -->`
+// A region can be named by adding a suffix to both markers, i.e. `<!-- godoc-readme:start:types -->` and `<!-- godoc-readme:end:types -->`
+// A marker has to be on its own line, so the markers quoted in inline code or in a code block are left alone
+var (
+	region_start_pattern = regexp.MustCompile(`^ {0,3}<!--\s*godoc-readme:start(?::([A-Za-z0-9_-]+))?\s*-->\s*$`)
+	region_end_pattern   = regexp.MustCompile(`^ {0,3}<!--\s*godoc-readme:end(?::([A-Za-z0-9_-]+))?\s*-->\s*$`)
+	code_fence_pattern   = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+)
+
+// region_marker is a start or end marker found in an existing README
+type region_marker struct {
+	// start and end are the offsets of the marker's line, without its line break
+	start, end int
+	name       string
+	is_end     bool
+}
+
+// merge_regions replaces the content between every pair of region markers in *existing* with the content returned by *render* for the region's name
+// The name of an unnamed region is an empty string. Everything outside of the markers, including the markers themselves, is left untouched.
+// If *existing* doesn't contain any markers, found is false and *merged* is nil
+func merge_regions(existing []byte, render func(name string) ([]byte, error)) (merged []byte, found bool, err error) {
+	var markers = region_markers(existing)
+	if len(markers) == 0 {
+		return nil, false, nil
+	}
+	var buf = bytes.NewBuffer(nil)
+	var rest_start = 0
+	for i := 0; i < len(markers); i += 2 {
+		var start = markers[i]
+		if start.is_end {
+			return nil, false, fmt.Errorf("godoc-readme region %q is missing its start marker", start.name)
+		}
+		if i+1 == len(markers) {
+			return nil, false, fmt.Errorf("godoc-readme region %q is missing its end marker", start.name)
+		}
+		var end = markers[i+1]
+		if !end.is_end {
+			return nil, false, fmt.Errorf("godoc-readme region %q contains another start marker, regions can't be nested", start.name)
+		}
+		if end.name != start.name {
+			return nil, false, fmt.Errorf("godoc-readme region %q is closed by the end marker of region %q", start.name, end.name)
+		}
+		var content []byte
+		if content, err = render(start.name); err != nil {
+			return nil, false, err
+		}
+		buf.Write(existing[rest_start:start.end])
+		buf.WriteString("\n")
+		buf.Write(bytes.Trim(content, "\n"))
+		buf.WriteString("\n")
+		rest_start = end.start
+	}
+	buf.Write(existing[rest_start:])
+	return buf.Bytes(), true, nil
+}
+
+// region_markers returns the region markers of a README in the order they appear
+// Only the markers on their own line outside of fenced code blocks are returned, the others are examples of the markers rather than markers
+func region_markers(text []byte) (markers []region_marker) {
+	var fence []byte
+	for offset := 0; offset < len(text); {
+		var line_end, next = len(text), len(text)
+		if i := bytes.IndexByte(text[offset:], '\n'); i >= 0 {
+			line_end, next = offset+i, offset+i+1
+		}
+		var line = bytes.TrimSuffix(text[offset:line_end], []byte("\r"))
+		var fence_match = code_fence_pattern.FindSubmatch(line)
+		switch {
+		case fence != nil:
+			// A fence is closed by a fence of the same character that's at least as long and has nothing after it
+			if fence_match != nil && fence_match[1][0] == fence[0] && len(fence_match[1]) >= len(fence) && len(bytes.TrimSpace(line[len(fence_match[0]):])) == 0 {
+				fence = nil
+			}
+		case fence_match != nil:
+			fence = fence_match[1]
+		default:
+			if match := region_start_pattern.FindSubmatchIndex(line); match != nil {
+				markers = append(markers, region_marker{start: offset, end: line_end, name: submatch(line, match, 1)})
+			} else if match := region_end_pattern.FindSubmatchIndex(line); match != nil {
+				markers = append(markers, region_marker{start: offset, end: line_end, name: submatch(line, match, 1), is_end: true})
+			}
+		}
+		offset = next
+	}
+	return
+}
+
+// submatch returns the text of the nth submatch of a match, or an empty string if the group didn't match
+func submatch(text []byte, match []int, n int) string {
+	if match[2*n] < 0 {
+		return ""
+	}
+	return string(text[match[2*n]:match[2*n+1]])
+}
@@ -0,0 +1,102 @@
+package godoc_readme
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func render_region_name(name string) ([]byte, error) {
+	return []byte("generated " + name + "\n"), nil
+}
+
+func TestMergeRegions(t *testing.T) {
+	have, found, err := merge_regions([]byte("# Intro\n<!-- godoc-readme:start -->\nold\n<!-- godoc-readme:end -->\n## Contributing\n"), render_region_name)
+	if err != nil || !found {
+		t.Fatalf("expected the region to be found without an error, got found=%t err=%v", found, err)
+	}
+	want := "# Intro\n<!-- godoc-readme:start -->\ngenerated \n<!-- godoc-readme:end -->\n## Contributing\n"
+	if string(have) != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+
+	have, _, err = merge_regions([]byte("badges\n<!-- godoc-readme:start:types -->\n<!-- godoc-readme:end:types -->\nhand written\n<!--godoc-readme:start:funcs-->\nold\n  <!--godoc-readme:end:funcs-->\n"), render_region_name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "badges\n<!-- godoc-readme:start:types -->\ngenerated types\n<!-- godoc-readme:end:types -->\nhand written\n<!--godoc-readme:start:funcs-->\ngenerated funcs\n  <!--godoc-readme:end:funcs-->\n"
+	if string(have) != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestMergeRegionsWithoutMarkers(t *testing.T) {
+	have, found, err := merge_regions([]byte("# README\n"), render_region_name)
+	if err != nil || found || have != nil {
+		t.Errorf("expected no regions, got found=%t err=%v merged=%q", found, err, have)
+	}
+}
+
+func TestMergeRegionsQuotedMarkers(t *testing.T) {
+	for _, existing := range []string{
+		"Add `<!-- godoc-readme:start -->` and `<!-- godoc-readme:end -->` to your README\n",
+		"```markdown\n<!-- godoc-readme:start -->\n<!-- godoc-readme:end -->\n```\n",
+		"~~~~\n<!-- godoc-readme:start -->\n~~~\n<!-- godoc-readme:end -->\n~~~~\n",
+		"    <!-- godoc-readme:start -->\n    <!-- godoc-readme:end -->\n",
+	} {
+		if have, found, err := merge_regions([]byte(existing), render_region_name); err != nil || found {
+			t.Errorf("expected the markers in %q to be ignored, got found=%t err=%v merged=%q", existing, found, err, have)
+		}
+	}
+}
+
+func TestMergeRegionsInvalidMarkers(t *testing.T) {
+	for _, existing := range []string{
+		"<!-- godoc-readme:start -->\nno end",
+		"<!-- godoc-readme:start:types -->\n<!-- godoc-readme:end:funcs -->",
+		"<!-- godoc-readme:start -->\n<!-- godoc-readme:start:types -->\n<!-- godoc-readme:end -->",
+		"<!-- godoc-readme:end -->\n<!-- godoc-readme:start -->\n<!-- godoc-readme:end -->",
+	} {
+		if _, _, err := merge_regions([]byte(existing), render_region_name); err == nil {
+			t.Errorf("expected an error for %q", existing)
+		}
+	}
+}
+
+func TestGenerateRegionsTwice(t *testing.T) {
+	var output_dir = t.TempDir()
+	var generate = func() []byte {
+		readme, err := NewReadme(func(ro *ReadmeOptions) {
+			ro.Dir = "./testdata/regions"
+			ro.PackageDir = "."
+			ro.OutputDir = output_dir
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = readme.Generate(); err != nil {
+			t.Fatal(err)
+		}
+		content, err := os.ReadFile(filepath.Join(output_dir, "godoc_readme", "testdata", "regions", "README.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return content
+	}
+	// The package doc quotes the markers, so they end up in the generated README without being regions
+	if first, second := generate(), generate(); !bytes.Equal(first, second) {
+		t.Errorf("expected the README to be the same when it's generated twice, have:\n%s\nthen:\n%s", first, second)
+	}
+	var hand_written = "# Hand-Written Title\n\n<!-- godoc-readme:start -->\n<!-- godoc-readme:end -->\n\n## Contributing\n"
+	if err := os.WriteFile(filepath.Join(output_dir, "godoc_readme", "testdata", "regions", "README.md"), []byte(hand_written), 0644); err != nil {
+		t.Fatal(err)
+	}
+	first, second := generate(), generate()
+	if !bytes.Equal(first, second) {
+		t.Errorf("expected the merged README to be the same when it's generated twice, have:\n%s\nthen:\n%s", first, second)
+	}
+	if bytes.Contains(first, []byte("# Package regions")) || bytes.Contains(first, []byte("DO NOT EDIT")) || !bytes.Contains(first, []byte("## Contributing")) {
+		t.Errorf("expected the unnamed region to be filled without the title and banner, have:\n%s", first)
+	}
+}
@@ -0,0 +1,82 @@
+package godoc_readme
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// git_source is the hosted repository that the `{owner}`, `{repo}` and `{ref}` placeholders of the `SourceURL` option are replaced with
+type git_source struct {
+	// root is the top-level directory of the repository that `{path}` is relative to
+	root    string
+	owner   string
+	repo    string
+	ref     string
+	ref_err error
+}
+
+// resolve_git_source reads the owner and name of the repository from the `origin` remote of the git repository containing *dir*
+// and the ref from the tag pointing at `HEAD`, or the commit hash of `HEAD` if it isn't tagged, so the links are permalinks
+// If there's no `origin` remote, the owner and name are taken from the module path, i.e. `github.com/{owner}/{repo}`
+// The root of the repository is *dir* without its path in the repository, so it's spelled like the file names of the packages even if *dir* is a symlink
+// If *dir* isn't in a git repository, *dir* is the root
+func resolve_git_source(dir string, module_path string) (source git_source) {
+	source.root = dir
+	if prefix, err := git(dir, "rev-parse", "--show-prefix"); err == nil && prefix != "" {
+		for range strings.Split(strings.Trim(prefix, "/"), "/") {
+			source.root = filepath.Dir(source.root)
+		}
+	}
+	if remote, err := git(dir, "remote", "get-url", "origin"); err == nil {
+		source.owner, source.repo = parse_remote_url(remote)
+	}
+	if source.owner == "" {
+		source.owner, source.repo = parse_remote_url(module_path)
+	}
+	if source.ref, source.ref_err = git(dir, "describe", "--tags", "--exact-match", "HEAD"); source.ref_err != nil {
+		source.ref, source.ref_err = git(dir, "rev-parse", "HEAD")
+	}
+	return
+}
+
+// validate returns an error if a placeholder used by the source url couldn't be resolved
+func (source git_source) validate(source_url string) error {
+	if source.ref_err != nil && strings.Contains(source_url, "{ref}") {
+		return fmt.Errorf("source url %q: the {ref} couldn't be resolved: %w", source_url, source.ref_err)
+	}
+	if source.owner == "" && (strings.Contains(source_url, "{owner}") || strings.Contains(source_url, "{repo}")) {
+		return fmt.Errorf("source url %q: the {owner} and {repo} couldn't be resolved from the origin remote or the module path", source_url)
+	}
+	return nil
+}
+
+// parse_remote_url returns the owner and name of a repository from its URL, i.e. `https://github.com/owner/repo.git`, `git@github.com:owner/repo.git` or `github.com/owner/repo`
+func parse_remote_url(remote string) (owner string, repo string) {
+	remote = strings.TrimSuffix(strings.TrimSpace(remote), ".git")
+	if _, path, found := strings.Cut(remote, "://"); found {
+		remote = path
+	} else {
+		// A scp-like URL, i.e. `git@github.com:owner/repo`
+		remote = strings.Replace(remote, ":", "/", 1)
+	}
+	var elements = strings.Split(strings.Trim(remote, "/"), "/")
+	if len(elements) < 3 {
+		return
+	}
+	return elements[1], elements[2]
+}
+
+// git runs the git command in *dir* and returns its trimmed output
+func git(dir string, args ...string) (output string, err error) {
+	var stderr bytes.Buffer
+	var cmd = exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+	var out []byte
+	if out, err = cmd.Output(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
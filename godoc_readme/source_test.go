@@ -0,0 +1,45 @@
+package godoc_readme
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseRemoteURL(t *testing.T) {
+	for remote, want := range map[string][2]string{
+		"https://github.com/dubbikins/godoc-readme.git":  {"dubbikins", "godoc-readme"},
+		"git@github.com:dubbikins/godoc-readme.git":      {"dubbikins", "godoc-readme"},
+		"ssh://git@github.com/dubbikins/godoc-readme":    {"dubbikins", "godoc-readme"},
+		"github.com/dubbikins/godoc-readme/godoc_readme": {"dubbikins", "godoc-readme"},
+		"example.com/module":                             {"", ""},
+	} {
+		if owner, repo := parse_remote_url(remote); owner != want[0] || repo != want[1] {
+			t.Errorf("%q: have %q/%q, want %q/%q", remote, owner, repo, want[0], want[1])
+		}
+	}
+}
+
+func TestGitSourceValidate(t *testing.T) {
+	var source = git_source{owner: "owner", repo: "repo", ref_err: errors.New("not a git repository")}
+	if err := source.validate("https://github.com/{owner}/{repo}/blob/main/{path}"); err != nil {
+		t.Errorf("expected a source url without {ref} to be valid, got %v", err)
+	}
+	if err := source.validate("https://github.com/{owner}/{repo}/blob/{ref}/{path}"); err == nil {
+		t.Errorf("expected an error for an unresolved {ref}")
+	}
+}
+
+func TestResolveGitSourceRoot(t *testing.T) {
+	dir, err := filepath.Abs("./testdata/regions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = git(dir, "rev-parse", "--show-toplevel"); err != nil {
+		t.Skipf("not in a git repository: %v", err)
+	}
+	var want = filepath.Dir(filepath.Dir(filepath.Dir(dir)))
+	if have := resolve_git_source(dir, "").root; have != want {
+		t.Errorf("expected the paths to be relative to the repository root, have %q, want %q", have, want)
+	}
+}
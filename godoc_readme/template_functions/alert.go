@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"go/doc"
+	"slices"
 
 	"golang.org/x/tools/go/packages"
 )
@@ -18,6 +19,9 @@ import (
 
 // CAUTION(Alert): Use this alert to caution users about serious issues
 
+// The note markers that are rendered as github markdown alerts
+var alert_types = []string{"NOTE", "WARNING", "IMPORTANT", "CAUTION", "TIP"}
+
 // Alert returns a function that, given the name of a target, returns a string representing the alerts for that target
 // Can be used in a template by calling `{{ Alert "target_name" }}` where `target_name` is the name of the package, a Type, Func, Var, or Const in the package.
 // Alerts are rendered AFTER the doc comment for the target by default. Provide your own templates to modify this behavior.
@@ -70,4 +74,14 @@ func Alert(pkg *packages.Package, notes map[string][]*doc.Note) func(string) str
 	}
 }
 
-
+// Notes returns the notes of a package that aren't rendered as github markdown alerts, keyed by their marker, i.e. `BUG`
+// Can be used in a template by calling `{{ range $marker, $notes := notes .Doc.Notes }}...{{ end }}`
+func Notes(notes map[string][]*doc.Note) map[string][]*doc.Note {
+	var filtered = map[string][]*doc.Note{}
+	for marker, marker_notes := range notes {
+		if !slices.Contains(alert_types, marker) {
+			filtered[marker] = marker_notes
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,211 @@
+package template_functions
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+/*
+ClassDiagram returns a function that renders a mermaid class diagram of the exported types of the package from its type information.
+
+  - A struct is a class with its exported fields and methods, an interface is an `<<interface>>` class with its methods
+  - An embedded type of the package is a composition, i.e. `Config *-- ConfigOptions`
+  - A field whose type refers to another type of the package is an association labeled with the field name, i.e. `Readme --> ReadmeOptions : options`
+  - A type that implements an interface of the package is a realization, i.e. `Shape <|.. Square`
+
+The diagram can be limited to some of the types by passing their names, i.e. `{{ class_diagram "Readme" "ReadmeOptions" }}`, it's empty if there are no types to draw.
+*/
+func ClassDiagram(pkg *types.Package) func(type_names ...string) string {
+	var qualifier = package_name_qualifier(pkg)
+	return func(type_names ...string) string {
+		if pkg == nil {
+			return ""
+		}
+		var classes []*types.TypeName
+		for _, name := range pkg.Scope().Names() {
+			type_object, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+			if !ok || !ast.IsExported(name) || type_object.IsAlias() {
+				continue
+			}
+			if len(type_names) > 0 && !contains(type_names, name) {
+				continue
+			}
+			classes = append(classes, type_object)
+		}
+		if len(classes) == 0 {
+			return ""
+		}
+		var in_diagram = map[*types.TypeName]bool{}
+		for _, class := range classes {
+			in_diagram[class] = true
+		}
+		var buf strings.Builder
+		var relations []string
+		buf.WriteString("```mermaid\nclassDiagram\n")
+		for _, class := range classes {
+			buf.WriteString(fmt.Sprintf("    class %s {\n", mermaid_name(class.Type(), qualifier)))
+			if types.IsInterface(class.Type()) {
+				buf.WriteString("        <<interface>>\n")
+			}
+			if underlying, ok := class.Type().Underlying().(*types.Struct); ok {
+				for i := 0; i < underlying.NumFields(); i++ {
+					var field = underlying.Field(i)
+					var related = related_types(field.Type(), in_diagram)
+					if field.Embedded() {
+						for _, embedded := range related {
+							relations = append(relations, fmt.Sprintf("    %s *-- %s", class.Name(), embedded.Name()))
+						}
+						continue
+					}
+					if !field.Exported() {
+						continue
+					}
+					buf.WriteString(fmt.Sprintf("        +%s %s\n", mermaid_type(field.Type(), qualifier), field.Name()))
+					for _, related_type := range related {
+						relations = append(relations, fmt.Sprintf("    %s --> %s : %s", class.Name(), related_type.Name(), field.Name()))
+					}
+				}
+			}
+			for _, method := range declared_methods(class) {
+				var signature = method.Type().(*types.Signature)
+				buf.WriteString(fmt.Sprintf("        +%s(%s)%s\n", method.Name(), mermaid_tuple(signature.Params(), signature.Variadic(), qualifier), mermaid_results(signature.Results(), qualifier)))
+			}
+			buf.WriteString("    }\n")
+		}
+		for _, iface := range classes {
+			if !types.IsInterface(iface.Type()) {
+				continue
+			}
+			for _, class := range classes {
+				if implemented, _ := implements(class, iface); implemented && !types.IsInterface(class.Type()) {
+					relations = append(relations, fmt.Sprintf("    %s <|.. %s", iface.Name(), class.Name()))
+				}
+			}
+		}
+		for _, relation := range relations {
+			buf.WriteString(relation + "\n")
+		}
+		buf.WriteString("```\n")
+		return buf.String()
+	}
+}
+
+// declared_methods returns the exported methods declared on the type, or the methods of an interface, sorted by name
+func declared_methods(type_object *types.TypeName) (methods []*types.Func) {
+	switch t := type_object.Type().(type) {
+	case *types.Named:
+		if iface, ok := t.Underlying().(*types.Interface); ok {
+			for i := 0; i < iface.NumMethods(); i++ {
+				methods = append(methods, iface.Method(i))
+			}
+			break
+		}
+		for i := 0; i < t.NumMethods(); i++ {
+			methods = append(methods, t.Method(i))
+		}
+	}
+	var exported = methods[:0]
+	for _, method := range methods {
+		if method.Exported() {
+			exported = append(exported, method)
+		}
+	}
+	sort.Slice(exported, func(i, j int) bool { return exported[i].Name() < exported[j].Name() })
+	return exported
+}
+
+// related_types returns the types of the diagram that a field type refers to, i.e. `ReadmeOptions` for `map[string]*ReadmeOptions`
+func related_types(t types.Type, in_diagram map[*types.TypeName]bool) (related []*types.TypeName) {
+	switch t := t.(type) {
+	case *types.Named:
+		if in_diagram[t.Origin().Obj()] {
+			related = append(related, t.Origin().Obj())
+		}
+		if type_args := t.TypeArgs(); type_args != nil {
+			for i := 0; i < type_args.Len(); i++ {
+				related = append(related, related_types(type_args.At(i), in_diagram)...)
+			}
+		}
+	case *types.Pointer:
+		return related_types(t.Elem(), in_diagram)
+	case *types.Slice:
+		return related_types(t.Elem(), in_diagram)
+	case *types.Array:
+		return related_types(t.Elem(), in_diagram)
+	case *types.Chan:
+		return related_types(t.Elem(), in_diagram)
+	case *types.Map:
+		return append(related_types(t.Key(), in_diagram), related_types(t.Elem(), in_diagram)...)
+	}
+	return
+}
+
+// mermaid_name returns the name of a named type in mermaid's generic syntax, i.e. `List~T~`
+func mermaid_name(t types.Type, qualifier types.Qualifier) string {
+	return strings.NewReplacer("[", "~", "]", "~").Replace(types.TypeString(t, qualifier))
+}
+
+// mermaid_type returns a type that can be written as a member of a mermaid class
+// Mermaid treats a member with parentheses as a method and braces as the end of the class, so func, struct and interface literals are shortened
+func mermaid_type(t types.Type, qualifier types.Qualifier) string {
+	switch t := t.(type) {
+	case *types.Named, *types.Alias:
+		return mermaid_name(t, qualifier)
+	case *types.Signature:
+		return "func"
+	case *types.Struct:
+		return "struct"
+	case *types.Interface:
+		if t.Empty() {
+			return "any"
+		}
+		return "interface"
+	case *types.Pointer:
+		return "*" + mermaid_type(t.Elem(), qualifier)
+	case *types.Slice:
+		return "[]" + mermaid_type(t.Elem(), qualifier)
+	case *types.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), mermaid_type(t.Elem(), qualifier))
+	case *types.Map:
+		return fmt.Sprintf("map[%s]%s", mermaid_type(t.Key(), qualifier), mermaid_type(t.Elem(), qualifier))
+	case *types.Chan:
+		return "chan " + mermaid_type(t.Elem(), qualifier)
+	}
+	return types.TypeString(t, qualifier)
+}
+
+// mermaid_tuple returns the comma separated types of the parameters
+func mermaid_tuple(tuple *types.Tuple, variadic bool, qualifier types.Qualifier) string {
+	var params []string
+	for i := 0; i < tuple.Len(); i++ {
+		var param = tuple.At(i).Type()
+		if variadic && i == tuple.Len()-1 {
+			if slice, ok := param.(*types.Slice); ok {
+				params = append(params, "..."+mermaid_type(slice.Elem(), qualifier))
+				continue
+			}
+		}
+		params = append(params, mermaid_type(param, qualifier))
+	}
+	return strings.Join(params, ", ")
+}
+
+// mermaid_results returns the results of a method after the parameters, mermaid doesn't support parentheses in the return type so they're left out
+func mermaid_results(results *types.Tuple, qualifier types.Qualifier) string {
+	if results.Len() == 0 {
+		return ""
+	}
+	return " " + mermaid_tuple(results, false, qualifier)
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
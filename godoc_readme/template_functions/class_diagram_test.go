@@ -0,0 +1,57 @@
+package template_functions
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassDiagram(t *testing.T) {
+	var pkg = check_package(t, `package p
+
+type Shape interface{ Area() float64 }
+
+type Base struct{ ID int }
+
+type Square struct {
+	Base
+	Side    float64
+	Parent  *Square
+	Shapes  map[string][]Shape
+	OnDraw  func(int) error
+	private string
+}
+
+func (*Square) Area() float64               { return 0 }
+func (s Square) Scale(factors ...float64) (Square, error) { return s, nil }
+func (Square) unexported()                   {}
+`)
+	var diagram = ClassDiagram(pkg)()
+	for _, want := range []string{
+		"```mermaid\nclassDiagram\n",
+		"    class Shape {\n        <<interface>>\n        +Area() float64\n    }\n",
+		"        +float64 Side\n",
+		"        +*Square Parent\n",
+		"        +map[string][]Shape Shapes\n",
+		"        +func OnDraw\n",
+		"        +Scale(...float64) Square, error\n",
+		"    Square *-- Base\n",
+		"    Square --> Square : Parent\n",
+		"    Square --> Shape : Shapes\n",
+		"    Shape <|.. Square\n",
+	} {
+		if !strings.Contains(diagram, want) {
+			t.Errorf("expected the diagram to contain %q, got:\n%s", want, diagram)
+		}
+	}
+	for _, unwanted := range []string{"private", "unexported", "+Base", "Shape <|.. Base"} {
+		if strings.Contains(diagram, unwanted) {
+			t.Errorf("expected the diagram not to contain %q, got:\n%s", unwanted, diagram)
+		}
+	}
+	if limited := ClassDiagram(pkg)("Square"); strings.Contains(limited, "class Shape") || strings.Contains(limited, "Square *-- Base") {
+		t.Errorf("expected only Square and its relations within the diagram, got:\n%s", limited)
+	}
+	if have := ClassDiagram(pkg)("Missing"); have != "" {
+		t.Errorf("expected no diagram without types, got %q", have)
+	}
+}
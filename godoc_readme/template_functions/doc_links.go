@@ -0,0 +1,144 @@
+package template_functions
+
+import (
+	"go/doc/comment"
+	"go/types"
+	"strings"
+)
+
+// DocOptions configures how the doc strings rendered by [DocStringWith] and [PackageDocStringWith] are parsed and printed
+type DocOptions struct {
+	// Parser parses the doc strings and resolves their doc links, usually `doc.Package.Parser()`
+	Parser *comment.Parser
+	// LinkURL returns the URL of a resolved doc link, a link with an empty URL is left as-is
+	LinkURL func(link *comment.DocLink) string
+	// HeadingLevel is the markdown heading level of a `# Heading` in the doc string, it defaults to 2
+	HeadingLevel int
+}
+
+// markdown parses the doc string and prints it as markdown
+// The fenced code blocks of the doc string, i.e. ```` ```go ````, are markdown rather than godoc, so they're copied as-is instead of being parsed
+func (options DocOptions) markdown(doc string, alerts bool) string {
+	var parser = options.Parser
+	if parser == nil {
+		parser = &comment.Parser{}
+	}
+	var heading_level = options.HeadingLevel
+	if heading_level == 0 {
+		heading_level = 2
+	}
+	var printer = &MarkdownPrinter{
+		HeadingLevel: heading_level,
+		LinkURL:      options.LinkURL,
+		Alerts:       alerts,
+	}
+	var segments []string
+	for i, segment := range split_code_fences(doc) {
+		if i%2 == 1 {
+			segments = append(segments, unindent_code_fence(segment))
+			continue
+		}
+		var parsed = parser.Parse(segment)
+		keep_explicit_headings(parsed, segment)
+		if text := printer.Markdown(parsed); text != "" {
+			segments = append(segments, text)
+		}
+	}
+	return strings.Join(segments, "\n")
+}
+
+// split_code_fences splits the doc string into the text outside of fenced code blocks, at the even indexes,
+// and the fenced code blocks including their fences, at the odd indexes
+func split_code_fences(doc string) (segments []string) {
+	var segment strings.Builder
+	var in_fence bool
+	for _, line := range strings.SplitAfter(doc, "\n") {
+		var is_fence = strings.HasPrefix(strings.TrimSpace(line), "```")
+		if is_fence && !in_fence {
+			segments = append(segments, segment.String())
+			segment.Reset()
+		}
+		segment.WriteString(line)
+		if is_fence && in_fence {
+			segments = append(segments, segment.String())
+			segment.Reset()
+		}
+		if is_fence {
+			in_fence = !in_fence
+		}
+	}
+	return append(segments, segment.String())
+}
+
+// unindent_code_fence removes the indentation that the lines of a fenced code block have in common, i.e. the tab gofmt adds to code in a doc comment
+func unindent_code_fence(fence string) string {
+	var lines = strings.Split(strings.TrimSuffix(fence, "\n"), "\n")
+	var prefix string
+	var found bool
+	for _, line := range lines[1 : len(lines)-1] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var indent = line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if !found {
+			prefix, found = indent, true
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	for i := 1; i < len(lines)-1; i++ {
+		lines[i] = strings.TrimPrefix(lines[i], prefix)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// keep_explicit_headings turns the old-style godoc headings, a single capitalized line without punctuation, back into paragraphs
+// In markdown they can't be told apart from a short sentence, so only the `# Heading` syntax is rendered as a heading
+func keep_explicit_headings(doc *comment.Doc, text string) {
+	for i, block := range doc.Content {
+		heading, ok := block.(*comment.Heading)
+		if !ok {
+			continue
+		}
+		var explicit bool
+		for _, line := range strings.Split(text, "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "# ") {
+				explicit = explicit || strings.TrimSpace(strings.TrimSpace(line)[2:]) == plain_text(heading.Text)
+			}
+		}
+		if !explicit {
+			doc.Content[i] = &comment.Paragraph{Text: heading.Text}
+		}
+	}
+}
+
+// DocLinkAnchor returns the anchor of the symbol in *pkg* that a doc link points to, i.e. `Readme.Generate` for `[Readme.Generate]`, see [Anchor]
+// It returns an empty string if the doc link doesn't name a symbol of the package
+func DocLinkAnchor(pkg *types.Package, link *comment.DocLink) string {
+	if link.Name == "" || pkg == nil {
+		return ""
+	}
+	if link.Recv != "" {
+		if _, ok := pkg.Scope().Lookup(link.Recv).(*types.TypeName); !ok {
+			return ""
+		}
+		return Anchor(link.Recv, link.Name)
+	}
+	if pkg.Scope().Lookup(link.Name) == nil {
+		return ""
+	}
+	return Anchor(link.Name)
+}
+
+// DocLinkPkgGoDev returns the pkg.go.dev URL of a doc link, i.e. `https://pkg.go.dev/go/doc#Example` for `[doc.Example]`
+func DocLinkPkgGoDev(link *comment.DocLink) string {
+	var url = "https://pkg.go.dev/" + link.ImportPath
+	switch {
+	case link.Recv != "":
+		url += "#" + link.Recv + "." + link.Name
+	case link.Name != "":
+		url += "#" + link.Name
+	}
+	return url
+}
@@ -0,0 +1,76 @@
+package template_functions
+
+import (
+	"go/doc/comment"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+func TestDocLinks(t *testing.T) {
+	var doc_links = DocStringWith(DocOptions{
+		Parser: &comment.Parser{
+			LookupSym: func(recv, name string) bool {
+				return name == "Readme" || (recv == "Readme" && name == "Generate")
+			},
+		},
+		LinkURL: func(link *comment.DocLink) string {
+			if link.ImportPath != "" {
+				return DocLinkPkgGoDev(link)
+			}
+			if link.Recv != "" {
+				return "#" + link.Recv + "." + link.Name
+			}
+			return "#" + link.Name
+		},
+	})
+	for _, test := range []struct{ have, want string }{
+		{"See [Readme] and [Readme.Generate].", "See [Readme](#Readme) and [Readme.Generate](#Readme.Generate)."},
+		{"Uses [encoding/json.Decoder].", "Uses [encoding/json.Decoder](https://pkg.go.dev/encoding/json#Decoder)."},
+		{"An [Unknown] symbol", "An [Unknown] symbol"},
+		{"A [Readme](./README.md) markdown link", "A [Readme](./README.md) markdown link"},
+		{"- [x] task", "- [x] task"},
+		{"`[Readme]` in code", "`[Readme]` in code"},
+		{"[Readme]\n\n\tvar r [Readme]\n", "[Readme](#Readme)\n\n```\nvar r [Readme]\n```"},
+		{"```go\nvar r [Readme]\n```\n[Readme]", "```go\nvar r [Readme]\n```\n\n[Readme](#Readme)"},
+		{"See [the docs].\n\n[the docs]: https://go.dev/doc", "See [the docs](https://go.dev/doc)."},
+	} {
+		if have := strings.TrimSuffix(doc_links(test.have), "\n"); have != test.want {
+			t.Errorf("have %q, want %q", have, test.want)
+		}
+	}
+}
+
+func TestHeadingSlug(t *testing.T) {
+	for heading, want := range map[string]string{
+		"func NewReadme":               "func-newreadme",
+		"Package `godoc_readme`":       "package-godoc_readme",
+		"Keeping Hand-Written Content": "keeping-hand-written-content",
+	} {
+		if have := HeadingSlug(heading); have != want {
+			t.Errorf("%q: have %q, want %q", heading, have, want)
+		}
+	}
+}
+
+func TestDocLinkAnchor(t *testing.T) {
+	var pkg = types.NewPackage("example.com/pkg", "pkg")
+	var readme = types.NewTypeName(token.NoPos, pkg, "Readme", nil)
+	pkg.Scope().Insert(readme)
+	pkg.Scope().Insert(types.NewConst(token.NoPos, pkg, "ConfigFileName", types.Typ[types.UntypedString], nil))
+	for _, test := range []struct {
+		link comment.DocLink
+		want string
+	}{
+		{comment.DocLink{Name: "Readme"}, "Readme"},
+		{comment.DocLink{Recv: "Readme", Name: "Generate"}, "Readme.Generate"},
+		{comment.DocLink{Name: "ConfigFileName"}, "ConfigFileName"},
+		{comment.DocLink{Name: "Unknown"}, ""},
+		{comment.DocLink{Recv: "Unknown", Name: "Generate"}, ""},
+	} {
+		if have := DocLinkAnchor(pkg, &test.link); have != test.want {
+			t.Errorf("have %q, want %q", have, test.want)
+		}
+	}
+}
@@ -6,5 +6,10 @@ This package provides a set of functions that are passed to the template engine
 The functions are used to format the documentation in a way that is easy to read and understand.
 
 You can utilize these functions in your own custom templates to generate documentation for your packages with customize formatting/behvior if the standard templates provided by godoc-readme do not meet your needs.
+
+@godoc-readme{
+	$Excludes => Types | Imports | Filenames
+	$SkipEmpty => true
+}
 */
 package template_functions
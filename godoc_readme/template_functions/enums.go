@@ -0,0 +1,184 @@
+package template_functions
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// EnumOptions configures the enums returned by [Enums]
+type EnumOptions struct {
+	// Doc configures how the doc and line comments of the constants are rendered
+	Doc DocOptions
+}
+
+// Enum is a type and the typed constants declared for it, i.e. `RenderFlag` and `RenderTypes`, `RenderFuncs`, ...
+type Enum struct {
+	// Type is the name of the type and Anchor is the anchor of its values table, i.e. `RenderFlag-values`
+	// The `-` can't be part of an identifier, so the anchor doesn't collide with the anchor of a method, i.e. `RenderFlag.Values`
+	Type   string
+	Anchor string
+	// Bitmask is true if most of the values are single bits, the values of a bitmask are also rendered in hex and binary
+	Bitmask bool
+	// Values are the exported constants of the type in the order they're declared
+	Values []*EnumValue
+}
+
+// EnumValue is a typed constant with its value evaluated by the type checker
+type EnumValue struct {
+	Name string
+	// Value is the evaluated value in decimal, i.e. `2.5` for a float, or the quoted value of a string constant
+	Value string
+	// Hex and Binary are the value in hex and binary, they're only set for the non-negative values of an integer type
+	// The binary values of a bitmask are as wide as its widest single bit, so the values that are wider, i.e. a value with every bit set, have no Binary
+	Hex    string
+	Binary string
+	// Doc is the doc comment of the constant, or its line comment if it has no doc comment, as a single line of markdown
+	Doc string
+}
+
+/*
+Enums returns a function that returns the exported constants of a type declared in the package, by name, with their values evaluated by the type checker.
+The values of `iota` constants, i.e. `RenderTypes RenderFlag = 1 << iota`, can't be read from their declaration, so they're rendered as a table under the type:
+
+	{{ with enum .Name }}{{ range .Values }}| {{ .Name }} | {{ .Value }} | {{ .Doc }} |
+	{{ end }}{{ end }}
+
+A type is a bitmask if most of its values are single bits and the values aren't a run of consecutive integers, the hex and binary values of a bitmask are rendered too.
+It returns nil if the type has no exported constants.
+*/
+func Enums(pkg *packages.Package, options EnumOptions) func(type_name string) *Enum {
+	var field_options = FieldOptions{Doc: options.Doc}
+	return func(type_name string) *Enum {
+		if pkg.Types == nil {
+			return nil
+		}
+		type_object, ok := pkg.Types.Scope().Lookup(type_name).(*types.TypeName)
+		if !ok {
+			return nil
+		}
+		var consts []*types.Const
+		for _, name := range pkg.Types.Scope().Names() {
+			const_object, ok := pkg.Types.Scope().Lookup(name).(*types.Const)
+			if !ok || !const_object.Exported() || !types.Identical(const_object.Type(), type_object.Type()) {
+				continue
+			}
+			if pkg.Fset != nil && strings.HasSuffix(pkg.Fset.Position(const_object.Pos()).Filename, "_test.go") {
+				continue
+			}
+			consts = append(consts, const_object)
+		}
+		if len(consts) == 0 {
+			return nil
+		}
+		sort.SliceStable(consts, func(i, j int) bool { return consts[i].Pos() < consts[j].Pos() })
+		var enum = &Enum{Type: type_name, Anchor: type_name + "-values", Bitmask: is_bitmask(consts)}
+		var docs = const_docs(pkg)
+		var width = 0
+		for _, const_object := range consts {
+			// The combined values of a bitmask, i.e. `RenderAll`, would pad every row to the size of the type
+			if value, exact := constant.Uint64Val(constant.ToInt(const_object.Val())); exact && (!enum.Bitmask || bits.OnesCount64(value) <= 1) {
+				width = max(width, bits.Len64(value))
+			}
+		}
+		for _, const_object := range consts {
+			var value = &EnumValue{Name: const_object.Name(), Value: table_cell(constant_value(const_object))}
+			if spec := docs[const_object.Name()]; spec != nil {
+				value.Doc = field_options.field_doc(&ast.Field{Doc: spec.Doc, Comment: spec.Comment})
+			}
+			if const_object.Val().Kind() == constant.Int {
+				if n, exact := constant.Uint64Val(const_object.Val()); exact {
+					value.Hex = fmt.Sprintf("0x%X", n)
+					if bits.Len64(n) <= max(width, 1) {
+						value.Binary = fmt.Sprintf("0b%0*b", max(width, 1), n)
+					}
+				}
+			}
+			enum.Values = append(enum.Values, value)
+		}
+		return enum
+	}
+}
+
+// constant_value returns the value of the constant in decimal
+// The exact value of a float is a fraction, i.e. `5/2`, so a float is formatted with the precision of its type and a complex number with [constant.Value.String] instead
+func constant_value(const_object *types.Const) string {
+	var value = const_object.Val()
+	switch value.Kind() {
+	case constant.Float:
+		var bit_size = 64
+		if basic, ok := const_object.Type().Underlying().(*types.Basic); ok && basic.Kind() == types.Float32 {
+			bit_size = 32
+		}
+		if f, _ := constant.Float64Val(value); !math.IsInf(f, 0) {
+			return strconv.FormatFloat(f, 'g', -1, bit_size)
+		}
+		return value.String()
+	case constant.Complex:
+		return value.String()
+	}
+	return value.ExactString()
+}
+
+// is_bitmask reports whether most of the integer values are single bits and they aren't a run of consecutive integers like the values of an `iota` enum
+func is_bitmask(consts []*types.Const) bool {
+	var values []uint64
+	var single_bits, others int
+	for _, const_object := range consts {
+		if const_object.Val().Kind() != constant.Int {
+			return false
+		}
+		var value, exact = constant.Uint64Val(const_object.Val())
+		if !exact {
+			return false
+		}
+		values = append(values, value)
+		switch {
+		case value == 0:
+		case bits.OnesCount64(value) == 1:
+			single_bits++
+		default:
+			others++
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	var consecutive = true
+	for i := 1; i < len(values); i++ {
+		if values[i] != values[i-1]+1 {
+			consecutive = false
+		}
+	}
+	return single_bits >= 2 && single_bits > others && !consecutive
+}
+
+// const_docs returns the specs of the package's constants by name, the spec of a constant holds its doc and line comment
+func const_docs(pkg *packages.Package) map[string]*ast.ValueSpec {
+	var specs = map[string]*ast.ValueSpec{}
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			gen_decl, ok := decl.(*ast.GenDecl)
+			if !ok || gen_decl.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen_decl.Specs {
+				value_spec, ok := spec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+				for _, name := range value_spec.Names {
+					specs[name.Name] = value_spec
+				}
+			}
+		}
+	}
+	return specs
+}
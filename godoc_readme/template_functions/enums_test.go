@@ -0,0 +1,107 @@
+package template_functions
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestEnums(t *testing.T) {
+	var fset = token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", `package p
+
+type Flag uint8
+
+const (
+	// FlagA is the first flag
+	FlagA Flag = 1 << iota
+	FlagB
+	FlagC // FlagC is the third flag
+	FlagNone Flag = 0
+	FlagAB = FlagA | FlagB
+	FlagAll = ^Flag(0)
+	flagHidden Flag = 8
+	// FlagE is the widest single bit, the binary values are as wide as it
+	FlagE Flag = 16
+)
+
+type Level int
+
+const (
+	Debug Level = iota
+	Info
+	Warn
+)
+
+type Name string
+
+const Default Name = "default"
+
+const Untyped = 1
+
+type Ratio float64
+
+const (
+	Half      Ratio = 0.5
+	TwoAndAHalf Ratio = 5.0 / 2
+	Third     Ratio = 1.0 / 3
+)
+
+type Small float32
+
+const Tenth Small = 0.1
+
+type Empty int
+`, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	types_pkg, err := (&types.Config{}).Check("example.com/p", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var enums = Enums(&packages.Package{Fset: fset, Syntax: []*ast.File{file}, Types: types_pkg}, EnumOptions{})
+
+	var flag = enums("Flag")
+	if flag == nil || !flag.Bitmask || flag.Anchor != "Flag-values" || len(flag.Values) != 7 {
+		t.Fatalf("expected the 7 exported flags of a bitmask, got %+v", flag)
+	}
+	for i, want := range []EnumValue{
+		{Name: "FlagA", Value: "1", Hex: "0x1", Binary: "0b00001", Doc: "FlagA is the first flag"},
+		{Name: "FlagB", Value: "2", Hex: "0x2", Binary: "0b00010"},
+		{Name: "FlagC", Value: "4", Hex: "0x4", Binary: "0b00100", Doc: "FlagC is the third flag"},
+		{Name: "FlagNone", Value: "0", Hex: "0x0", Binary: "0b00000"},
+		{Name: "FlagAB", Value: "3", Hex: "0x3", Binary: "0b00011"},
+		{Name: "FlagAll", Value: "255", Hex: "0xFF"},
+		{Name: "FlagE", Value: "16", Hex: "0x10", Binary: "0b10000", Doc: "FlagE is the widest single bit, the binary values are as wide as it"},
+	} {
+		if *flag.Values[i] != want {
+			t.Errorf("value %d: have %+v, want %+v", i, *flag.Values[i], want)
+		}
+	}
+	if level := enums("Level"); level == nil || level.Bitmask || len(level.Values) != 3 || level.Values[2].Value != "2" {
+		t.Errorf("expected the 3 levels of an iota enum that isn't a bitmask, got %+v", level)
+	}
+	if name := enums("Name"); name == nil || name.Bitmask || name.Values[0].Value != `"default"` || name.Values[0].Hex != "" {
+		t.Errorf("expected the quoted value of a string constant, got %+v", name)
+	}
+	var ratio = enums("Ratio")
+	if ratio == nil || ratio.Bitmask || len(ratio.Values) != 3 {
+		t.Fatalf("expected the 3 ratios, got %+v", ratio)
+	}
+	for i, want := range []string{"0.5", "2.5", "0.3333333333333333"} {
+		if have := ratio.Values[i].Value; have != want {
+			t.Errorf("expected a float to be rendered in decimal, have %q, want %q", have, want)
+		}
+	}
+	if small := enums("Small"); small == nil || small.Values[0].Value != "0.1" {
+		t.Errorf("expected a float32 to be rendered with its precision, got %+v", small)
+	}
+	if empty := enums("Empty"); empty != nil {
+		t.Errorf("expected no enum for a type without constants, got %+v", empty)
+	}
+}
@@ -0,0 +1,127 @@
+package template_functions
+
+import (
+	"go/ast"
+	"go/doc/comment"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// EnvVarOptions configures the environment variables returned by [EnvVars]
+type EnvVarOptions struct {
+	// Tags are the struct tags that name an environment variable, i.e. `env` for `env:"PORT"` or `envconfig` for `envconfig:"PORT"`
+	// When a field has more than one of the tags, the first one in the list is used
+	Tags []string
+	// LinkURL returns the URL of a doc link to a type of the package, it links the owning struct and the type of the variable
+	LinkURL func(link *comment.DocLink) string
+	// Doc configures how the doc and line comments of the fields are rendered
+	Doc DocOptions
+}
+
+// EnvVar is an environment variable read into a field of a struct, ready to be rendered as a row of a markdown table
+type EnvVar struct {
+	// Name is the name of the variable, i.e. `GODOC_README_RENDER`
+	Name string
+	// Tag is the struct tag the name was read from, i.e. `env`
+	Tag string
+	// Type is the type of the field as it's written in the source
+	Type    string
+	TypeURL string
+	// Struct is the struct that owns the field and Field is the name of the field
+	Struct    string
+	StructURL string
+	Field     string
+	// Default is the value of the field's `default` tag
+	Default string
+	// Required is true if the field's `required` tag is `true`
+	Required bool
+	// Doc is the doc comment of the field, or its line comment if it has no doc comment, as a single line of markdown
+	Doc string
+}
+
+/*
+EnvVars returns a function that returns the environment variables the structs of the package are filled from, in the order they're declared.
+A variable is a struct field with one of the tags of the options, i.e. `env:"PORT"`, the fields tagged with `env:"-"` are skipped.
+The name of the variable is the tag value up to the first `,` and the `default` and `required` tags of the field are included,
+so the variables can be rendered as an operator-facing reference of the configuration:
+
+	{{ range env_vars }}| {{ .Name }} | {{ .Type }} | {{ .Default }} | {{ .Struct }}.{{ .Field }} | {{ .Doc }} |
+	{{ end }}
+*/
+func EnvVars(pkg *packages.Package, options EnvVarOptions) func() []*EnvVar {
+	var field_options = FieldOptions{LinkURL: options.LinkURL, Doc: options.Doc}
+	return func() (env_vars []*EnvVar) {
+		for _, file := range pkg.Syntax {
+			if pkg.Fset != nil && strings.HasSuffix(pkg.Fset.Position(file.Pos()).Filename, "_test.go") {
+				continue
+			}
+			for _, decl := range file.Decls {
+				gen_decl, ok := decl.(*ast.GenDecl)
+				if !ok || gen_decl.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen_decl.Specs {
+					type_spec, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					struct_type, ok := type_spec.Type.(*ast.StructType)
+					if !ok || struct_type.Fields == nil {
+						continue
+					}
+					for _, field := range struct_type.Fields.List {
+						env_vars = append(env_vars, field_options.env_vars(pkg, type_spec.Name.Name, field, options.Tags)...)
+					}
+				}
+			}
+		}
+		return
+	}
+}
+
+// env_vars returns the environment variable of each name of the field if it has one of the tags
+func (options FieldOptions) env_vars(pkg *packages.Package, struct_name string, field *ast.Field, tag_names []string) (env_vars []*EnvVar) {
+	var tags = map[string]string{}
+	for _, tag := range field_tags(field) {
+		tags[tag.Key] = tag.Value
+	}
+	for _, tag_name := range tag_names {
+		var value, found = tags[tag_name]
+		if !found {
+			continue
+		}
+		var name, _, _ = strings.Cut(value, ",")
+		if name == "" || name == "-" {
+			return
+		}
+		var names []string
+		for _, field_name := range field.Names {
+			names = append(names, field_name.Name)
+		}
+		if len(names) == 0 {
+			names = append(names, embedded_field_name(field.Type))
+		}
+		var struct_url string
+		if options.LinkURL != nil {
+			struct_url = options.LinkURL(&comment.DocLink{Name: struct_name})
+		}
+		for _, field_name := range names {
+			env_vars = append(env_vars, &EnvVar{
+				Name:      table_cell(name),
+				Tag:       tag_name,
+				Type:      field_type_string(pkg.Fset, field.Type),
+				TypeURL:   options.local_type_url(pkg, field.Type),
+				Struct:    struct_name,
+				StructURL: struct_url,
+				Field:     field_name,
+				Default:   tags["default"],
+				Required:  tags["required"] == "true",
+				Doc:       options.field_doc(field),
+			})
+		}
+		return
+	}
+	return
+}
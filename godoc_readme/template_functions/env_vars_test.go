@@ -0,0 +1,58 @@
+package template_functions
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestEnvVars(t *testing.T) {
+	var fset = token.NewFileSet()
+	var pkg = &packages.Package{Fset: fset}
+	for name, src := range map[string]string{
+		"config.go": `package p
+
+type Config struct {
+	// Port is the port to listen on
+	Port   int    ` + "`" + `env:"PORT" default:"8080"` + "`" + `
+	Secret string ` + "`" + `envconfig:"SECRET,optional" env:"APP_SECRET" required:"true"` + "`" + ` // Secret signs the tokens
+	Writer any    ` + "`" + `env:"-"` + "`" + `
+	Name   string
+}
+`,
+		"config_test.go": `package p
+
+type TestConfig struct {
+	Debug bool ` + "`" + `env:"DEBUG"` + "`" + `
+}
+`,
+	} {
+		file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkg.Syntax = append(pkg.Syntax, file)
+	}
+	var have = EnvVars(pkg, EnvVarOptions{Tags: []string{"env", "envconfig"}})()
+	var want = []EnvVar{
+		{Name: "PORT", Tag: "env", Type: "int", Struct: "Config", Field: "Port", Default: "8080", Doc: "Port is the port to listen on"},
+		{Name: "APP_SECRET", Tag: "env", Type: "string", Struct: "Config", Field: "Secret", Required: true, Doc: "Secret signs the tokens"},
+	}
+	if len(have) != len(want) {
+		t.Fatalf("expected %d variables, got %d", len(want), len(have))
+	}
+	for i := range want {
+		if *have[i] != want[i] {
+			t.Errorf("variable %d: have %+v, want %+v", i, *have[i], want[i])
+		}
+	}
+	if have := EnvVars(pkg, EnvVarOptions{Tags: []string{"envconfig"}})(); len(have) != 1 || have[0].Name != "SECRET" {
+		t.Errorf("expected only the envconfig variable, got %v", have)
+	}
+	if have := EnvVars(&packages.Package{Fset: fset, Syntax: []*ast.File{}}, EnvVarOptions{Tags: []string{"env"}})(); have != nil {
+		t.Errorf("expected no variables, got %v", have)
+	}
+}
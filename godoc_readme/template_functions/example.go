@@ -3,31 +3,154 @@ package template_functions
 import (
 	"bytes"
 	"fmt"
+	"go/ast"
 	"go/doc"
 	"go/format"
+	"go/printer"
+	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/tools/go/packages"
 )
 
+// The output comment of an example, i.e. `// Output:` or `// Unordered output:`
+var example_output_pattern = regexp.MustCompile(`(?i)^\s*(unordered )?output:`)
+
+// The blank line left at the end of an example's body by its output comment
+var trailing_blank_line_pattern = regexp.MustCompile(`\n\s*\n}$`)
+
+// ExampleResult is the result of running an example with `go test`
+type ExampleResult struct {
+	// Passed is true if the output of the example matched its `// Output:` comment
+	Passed bool
+	// Output is the actual output of a failed example, it's only set if HasOutput is true
+	Output    string
+	HasOutput bool
+}
+
+// ExampleOptions configures how the examples rendered by [ExampleCodeWith] are rendered
+type ExampleOptions struct {
+	// Results are the results of running the examples, by example func name, i.e. `ExampleReadme_Generate`
+	// The actual output of an example that was run replaces its `// Output:` comment and a failed example is marked with a warning alert
+	Results map[string]*ExampleResult
+	// Doc configures how the doc comment of an example is rendered
+	Doc DocOptions
+}
+
 // ExampleCode returns a function, given a package containing the example code, that returns a string representation of a doc.Example (Example Function in a package)
 // You can call this function in a template by using `{{ example . }}` where `.` is a `*doc.Example` instance
 func ExampleCode(pkg *packages.Package) func(*doc.Example) string {
+	return ExampleCodeWith(pkg, ExampleOptions{})
+}
+
+/*
+ExampleCodeWith returns a function that renders a doc.Example like [ExampleCode], with the output of the examples that were run
+
+An example is rendered as a collapsed section labeled with the symbol it belongs to and its suffix, i.e. `Example Readme.Generate (Basic)` for `ExampleReadme_Generate_basic`.
+The doc comment of the example is rendered as prose above its code and the expected output, or the `Unordered output`, is rendered below it.
+An example without an `// Output:` comment is rendered without an Output block.
+A whole-file example is rendered as the complete runnable program, including its imports.
+*/
+func ExampleCodeWith(pkg *packages.Package, options ExampleOptions) func(*doc.Example) string {
 
 	return func(ex *doc.Example) string {
-		
+		var output, has_output = ex.Output, ex.Output != "" || ex.EmptyOutput
+		var result = options.Results["Example"+ex.Name]
+		if result != nil && !result.Passed && result.HasOutput {
+			output, has_output = result.Output, true
+		}
 		var buf = bytes.NewBuffer(nil)
+		if result != nil && !result.Passed {
+			// The blank line ends the quoted doc comment the example follows, otherwise the alert would continue its quote rather than start an alert
+			buf.WriteString("\n> [!WARNING]\n")
+			if result.HasOutput {
+				buf.WriteString(fmt.Sprintf("> The output of `Example%s` doesn't match its `// Output:` comment, the output shown is the actual output of `go test`\n\n", ex.Name))
+			} else {
+				buf.WriteString(fmt.Sprintf("> `Example%s` failed when it was run with `go test`\n\n", ex.Name))
+			}
+		}
 		buf.WriteString("<details>\n")
-		buf.WriteString(fmt.Sprintf("<summary>Example%s</summary>\n\n", ex.Name))
-		buf.WriteString(fmt.Sprintf("```go\nfunc Example%s", ex.Name))
-		format.Node(buf, pkg.Fset, ex.Code)
-		output_lines := strings.Split(ex.Output, "\n")
-		buf.WriteString("\n // Output:")
-		for _, line := range output_lines {
-			buf.WriteString(fmt.Sprintf("\n // %s", line))
+		buf.WriteString(fmt.Sprintf("<summary><a id=\"%s\"></a>%s</summary>\n\n", ExampleAnchor(ex), ExampleLabel(ex)))
+		if ex.Doc != "" {
+			buf.WriteString(options.Doc.markdown(ex.Doc, false))
+			buf.WriteString("\n")
 		}
+		var code = bytes.NewBuffer(nil)
+		if file, whole_file := ex.Code.(*ast.File); whole_file {
+			if ex.Play != nil {
+				file = ex.Play
+			}
+			format.Node(code, pkg.Fset, file)
+		} else {
+			code.WriteString(fmt.Sprintf("func Example%s() ", ex.Name))
+			format.Node(code, pkg.Fset, &printer.CommentedNode{Node: ex.Code, Comments: example_comments(ex)})
+		}
+		buf.WriteString("```go\n")
+		buf.WriteString(trailing_blank_line_pattern.ReplaceAllString(strings.TrimRight(code.String(), "\n"), "\n}"))
 		buf.WriteString("\n```\n\n") // code blocks should be followed by a blank line
-		buf.WriteString("</details>\n") 
+		if has_output {
+			if ex.Unordered {
+				buf.WriteString("Unordered output:\n\n")
+			} else {
+				buf.WriteString("Output:\n\n")
+			}
+			buf.WriteString("```\n")
+			if output = strings.TrimSuffix(output, "\n"); output != "" {
+				buf.WriteString(output + "\n")
+			}
+			buf.WriteString("```\n\n")
+		}
+		buf.WriteString("</details>\n")
 		return buf.String()
 	}
 }
+
+// ExampleAnchor returns the id of the `<a id="...">` anchor in the summary of a rendered example, the name of its example func, i.e. `ExampleReadme_Generate_basic`
+// Usage: `[Example](#{{ example_anchor . }})`
+func ExampleAnchor(ex *doc.Example) string {
+	return "Example" + ex.Name
+}
+
+// ExampleLabel returns a readable label of an example, i.e. `Example Readme.Generate (Basic)` for `ExampleReadme_Generate_basic`
+// A package example is labeled `Example`, or `Example (Suffix)` if it has a suffix
+func ExampleLabel(ex *doc.Example) string {
+	var name, suffix = ex.Name, ex.Suffix
+	if suffix == "" {
+		// The suffix is only set by doc.NewFromFiles, the suffix starts with a lower case letter
+		if index := strings.LastIndex(name, "_"); index >= 0 {
+			if r, _ := utf8.DecodeRuneInString(name[index+1:]); unicode.IsLower(r) {
+				suffix = name[index+1:]
+			}
+		}
+	}
+	if suffix != "" {
+		name = strings.TrimSuffix(strings.TrimSuffix(name, suffix), "_")
+	}
+	var label = "Example"
+	if name != "" {
+		label += " " + strings.ReplaceAll(name, "_", ".")
+	}
+	if suffix != "" {
+		var r, size = utf8.DecodeRuneInString(suffix)
+		label += fmt.Sprintf(" (%c%s)", unicode.ToUpper(r), suffix[size:])
+	}
+	return label
+}
+
+// example_comments returns the comments in the body of an example without its output comment, which is rendered separately
+func example_comments(ex *doc.Example) (comments []*ast.CommentGroup) {
+	var output_comment *ast.CommentGroup
+	for _, comment := range ex.Comments {
+		if comment.Pos() < ex.Code.Pos() || comment.End() > ex.Code.End() {
+			continue
+		}
+		comments = append(comments, comment)
+		output_comment = comment
+	}
+	if output_comment != nil && example_output_pattern.MatchString(output_comment.Text()) {
+		comments = comments[:len(comments)-1]
+	}
+	return
+}
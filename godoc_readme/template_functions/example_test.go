@@ -0,0 +1,141 @@
+package template_functions
+
+import (
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestExampleLabel(t *testing.T) {
+	for _, test := range []struct {
+		example doc.Example
+		want    string
+	}{
+		{doc.Example{Name: ""}, "Example"},
+		{doc.Example{Name: "_basic"}, "Example (Basic)"},
+		{doc.Example{Name: "NewReadme"}, "Example NewReadme"},
+		{doc.Example{Name: "Readme_Generate"}, "Example Readme.Generate"},
+		{doc.Example{Name: "Readme_Generate_check", Suffix: "check"}, "Example Readme.Generate (Check)"},
+	} {
+		if have := ExampleLabel(&test.example); have != test.want {
+			t.Errorf("%q: have %q, want %q", test.example.Name, have, test.want)
+		}
+	}
+}
+
+func TestExampleCode(t *testing.T) {
+	var fset = token.NewFileSet()
+	file, err := parser.ParseFile(fset, "example_test.go", `package pkg
+
+// Prints the keys in any order.
+func ExampleKeys_unordered() {
+	// print the keys
+	println("b")
+	println("a")
+	// Unordered output:
+	// a
+	// b
+}
+
+func ExampleKeys_none() {
+	println("a")
+}
+`, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var examples = doc.Examples(file)
+	example := ExampleCode(&packages.Package{Fset: fset})
+	have := example(examples[1])
+	for _, want := range []string{
+		"<summary><a id=\"ExampleKeys_unordered\"></a>Example Keys (Unordered)</summary>",
+		"Prints the keys in any order.\n",
+		"```go\nfunc ExampleKeys_unordered() {\n\t// print the keys\n\tprintln(\"b\")\n\tprintln(\"a\")\n}\n```",
+		"Unordered output:\n\n```\na\nb\n```",
+	} {
+		if !strings.Contains(have, want) {
+			t.Errorf("expected the example to contain %q, have:\n%s", want, have)
+		}
+	}
+	if have = example(examples[0]); strings.Contains(have, "output:") || strings.Contains(have, "Output:") {
+		t.Errorf("expected an example without an output comment to have no Output block, have:\n%s", have)
+	}
+}
+
+func TestExampleCodeFailedAlert(t *testing.T) {
+	var fset = token.NewFileSet()
+	file, err := parser.ParseFile(fset, "example_test.go", `package pkg
+
+func ExampleKeys() {
+	println("a")
+	// Output:
+	// a
+}
+`, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	example := ExampleCodeWith(&packages.Package{Fset: fset}, ExampleOptions{
+		Results: map[string]*ExampleResult{"ExampleKeys": {HasOutput: true, Output: "b\n"}},
+	})
+	// An example is rendered right after the quoted doc comment of the symbol it belongs to, see the `.Func.tmpl` template
+	have := Section("Keys returns the keys\n", 1) + example(doc.Examples(file)[0])
+	for _, want := range []string{
+		">Keys returns the keys\n\n> [!WARNING]\n> The output of `ExampleKeys` doesn't match",
+		"Output:\n\n```\nb\n```",
+	} {
+		if !strings.Contains(have, want) {
+			t.Errorf("expected the rendered example to contain %q, have:\n%s", want, have)
+		}
+	}
+}
+
+func TestExampleCodeWholeFile(t *testing.T) {
+	var fset = token.NewFileSet()
+	file, err := parser.ParseFile(fset, "whole_file_example_test.go", `package pkg_test
+
+import (
+	"fmt"
+	"strings"
+)
+
+type upper string
+
+func (u upper) String() string {
+	return strings.ToUpper(string(u))
+}
+
+const greeting = "hello"
+
+func Example() {
+	fmt.Println(upper(greeting))
+	// Output:
+	// HELLO
+}
+`, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var examples = doc.Examples(file)
+	if len(examples) != 1 || examples[0].Play == nil {
+		t.Fatalf("expected a whole-file example with a playable program, have %+v", examples)
+	}
+	have := ExampleCode(&packages.Package{Fset: fset})(examples[0])
+	for _, want := range []string{
+		"```go\npackage main\n",
+		"import (\n\t\"fmt\"\n\t\"strings\"\n)\n",
+		"type upper string\n",
+		"func (u upper) String() string {\n\treturn strings.ToUpper(string(u))\n}\n",
+		"const greeting = \"hello\"\n",
+		"func main() {\n\tfmt.Println(upper(greeting))\n}\n",
+		"Output:\n\n```\nHELLO\n```",
+	} {
+		if !strings.Contains(have, want) {
+			t.Errorf("expected the whole-file example to contain %q, have:\n%s", want, have)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package template_functions
+
+import (
+	"bytes"
+	"go/ast"
+	"go/doc/comment"
+	"go/format"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// FieldOptions configures the struct fields returned by [Fields]
+type FieldOptions struct {
+	// LinkURL returns the URL of a doc link to a type of the package, the type of a field that isn't linked is rendered as code
+	LinkURL func(link *comment.DocLink) string
+	// Doc configures how the doc and line comments of the fields are rendered
+	Doc DocOptions
+}
+
+// Field is an exported field of a struct, ready to be rendered as a row of a markdown table
+type Field struct {
+	// Name is the name of the field, or the name of the type of an embedded field
+	Name     string
+	Embedded bool
+	// Type is the type of the field as it's written in the source, i.e. `map[string]*packages.Package`
+	Type string
+	// TypeURL links the type of the field to the type's section if the type is declared in the package
+	TypeURL string
+	// Tags are the key/value pairs of the field's tag in the order they're written, i.e. `env:"GODOC_README_RENDER" default:"default"`
+	Tags []*FieldTag
+	// Doc is the doc comment of the field, or its line comment if it has no doc comment, as a single line of markdown
+	Doc string
+}
+
+// FieldTag is a key/value pair of a struct tag, i.e. `json:"name,omitempty"`
+type FieldTag struct {
+	Key   string
+	Value string
+}
+
+/*
+Fields returns a function that returns the exported fields of a struct type declared in the package, by name, in the order they're declared.
+It's the table view of a struct, as opposed to the code view of `gen_decl`, so a template can choose between them:
+
+	{{ with fields .Name }}| Field | Type | Tags | Doc |
+	| --- | --- | --- | --- |
+	{{ range . }}| {{ .Name }} | {{ .Type }} | {{ range .Tags }}{{ .Key }}: {{ .Value }} {{ end }} | {{ .Doc }} |
+	{{ end }}{{ else }}{{ gen_decl .Decl }}{{ end }}
+
+It returns nil if the type isn't a struct or doesn't have any exported fields.
+*/
+func Fields(pkg *packages.Package, options FieldOptions) func(type_name string) []*Field {
+	return func(type_name string) (fields []*Field) {
+		var struct_type = struct_type_spec(pkg, type_name)
+		if struct_type == nil || struct_type.Fields == nil {
+			return
+		}
+		for _, ast_field := range struct_type.Fields.List {
+			var names []string
+			for _, name := range ast_field.Names {
+				names = append(names, name.Name)
+			}
+			var embedded = len(names) == 0
+			if embedded {
+				names = append(names, embedded_field_name(ast_field.Type))
+			}
+			var field = &Field{
+				Embedded: embedded,
+				Type:     field_type_string(pkg.Fset, ast_field.Type),
+				TypeURL:  options.local_type_url(pkg, ast_field.Type),
+				Tags:     field_tags(ast_field),
+				Doc:      options.field_doc(ast_field),
+			}
+			for _, name := range names {
+				if !ast.IsExported(name) {
+					continue
+				}
+				var named_field = *field
+				named_field.Name = name
+				fields = append(fields, &named_field)
+			}
+		}
+		return
+	}
+}
+
+// struct_type_spec returns the struct type of the type spec with the name, or nil if the package doesn't declare a struct with the name
+func struct_type_spec(pkg *packages.Package, type_name string) *ast.StructType {
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			gen_decl, ok := decl.(*ast.GenDecl)
+			if !ok || gen_decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen_decl.Specs {
+				if type_spec, ok := spec.(*ast.TypeSpec); ok && type_spec.Name.Name == type_name {
+					struct_type, _ := type_spec.Type.(*ast.StructType)
+					return struct_type
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// embedded_field_name returns the name of an embedded field, which is the name of its type without the package and type parameters
+func embedded_field_name(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return embedded_field_name(expr.X)
+	case *ast.SelectorExpr:
+		return expr.Sel.Name
+	case *ast.IndexExpr:
+		return embedded_field_name(expr.X)
+	case *ast.IndexListExpr:
+		return embedded_field_name(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	}
+	return ""
+}
+
+// field_type_string returns the type of a field as it's written in the source, on a single line that can be put in a table cell
+func field_type_string(fset *token.FileSet, expr ast.Expr) string {
+	var buf = bytes.NewBuffer(nil)
+	format.Node(buf, fset, expr)
+	return table_cell(strings.Join(strings.Fields(buf.String()), " "))
+}
+
+// local_type_url returns the link to the named type of the package that the field type refers to, i.e. `ReadmeOptions` for `*ReadmeOptions`
+func (options FieldOptions) local_type_url(pkg *packages.Package, expr ast.Expr) string {
+	if options.LinkURL == nil || pkg.TypesInfo == nil {
+		return ""
+	}
+	var field_type = pkg.TypesInfo.TypeOf(expr)
+	for field_type != nil {
+		switch t := field_type.(type) {
+		case *types.Pointer:
+			field_type = t.Elem()
+		case *types.Slice:
+			field_type = t.Elem()
+		case *types.Array:
+			field_type = t.Elem()
+		case *types.Map:
+			field_type = t.Elem()
+		case *types.Chan:
+			field_type = t.Elem()
+		case *types.Named:
+			if t.Obj().Pkg() != pkg.Types {
+				return ""
+			}
+			return options.LinkURL(&comment.DocLink{Name: t.Obj().Name()})
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// field_doc returns the doc comment of the field, or its line comment, as a single line of markdown
+func (options FieldOptions) field_doc(field *ast.Field) string {
+	var text = field.Doc.Text()
+	if text == "" {
+		text = field.Comment.Text()
+	}
+	if text == "" {
+		return ""
+	}
+	return table_cell(options.Doc.markdown(text, false))
+}
+
+// field_tags parses the tag of the field into its key/value pairs with the same rules as `reflect.StructTag`
+func field_tags(field *ast.Field) (tags []*FieldTag) {
+	if field.Tag == nil {
+		return
+	}
+	var tag, err = strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return
+	}
+	for tag != "" {
+		tag = strings.TrimLeft(tag, " ")
+		var i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			return
+		}
+		var key = tag[:i]
+		tag = tag[i+1:]
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return
+		}
+		var value, err = strconv.Unquote(tag[:i+1])
+		if err != nil {
+			return
+		}
+		tags = append(tags, &FieldTag{Key: key, Value: table_cell(value)})
+		tag = tag[i+1:]
+	}
+	return
+}
+
+// table_cell fits markdown in a single table cell: paragraphs are separated by `<br>`, the other line breaks are joined and `|` is escaped
+func table_cell(markdown string) string {
+	var paragraphs = strings.Split(strings.TrimSpace(markdown), "\n\n")
+	for i, paragraph := range paragraphs {
+		paragraphs[i] = strings.Join(strings.Fields(paragraph), " ")
+	}
+	return strings.ReplaceAll(strings.Join(paragraphs, "<br>"), "|", `\|`)
+}
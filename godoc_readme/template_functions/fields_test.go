@@ -0,0 +1,69 @@
+package template_functions
+
+import (
+	"go/ast"
+	"go/doc/comment"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestFields(t *testing.T) {
+	var fset = token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", `package p
+
+type Options struct {
+	// Name is the name
+	Name    string `+"`"+`env:"P_NAME" default:"p|q"`+"`"+`
+	Mode    *Mode // Mode selects the mode
+	Timeout, Delay int64
+	Base
+	hidden  int
+}
+
+type Base struct{}
+
+type Mode int
+`, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info = &types.Info{Types: map[ast.Expr]types.TypeAndValue{}}
+	types_pkg, err := (&types.Config{}).Check("example.com/p", fset, []*ast.File{file}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pkg = &packages.Package{Fset: fset, Syntax: []*ast.File{file}, Types: types_pkg, TypesInfo: info}
+	var fields = Fields(pkg, FieldOptions{LinkURL: func(link *comment.DocLink) string { return "#" + link.Name }})
+	var have = fields("Options")
+	var want = []Field{
+		{Name: "Name", Type: "string", Tags: []*FieldTag{{"env", "P_NAME"}, {"default", `p\|q`}}, Doc: "Name is the name"},
+		{Name: "Mode", Type: "*Mode", TypeURL: "#Mode", Doc: "Mode selects the mode"},
+		{Name: "Timeout", Type: "int64"},
+		{Name: "Delay", Type: "int64"},
+		{Name: "Base", Type: "Base", TypeURL: "#Base", Embedded: true},
+	}
+	if len(have) != len(want) {
+		t.Fatalf("expected %d fields, got %d", len(want), len(have))
+	}
+	for i := range want {
+		if have[i].Name != want[i].Name || have[i].Type != want[i].Type || have[i].TypeURL != want[i].TypeURL || have[i].Embedded != want[i].Embedded || have[i].Doc != want[i].Doc {
+			t.Errorf("field %d: have %+v, want %+v", i, *have[i], want[i])
+		}
+		if len(have[i].Tags) != len(want[i].Tags) {
+			t.Errorf("field %d: expected the tags %v, got %v", i, want[i].Tags, have[i].Tags)
+			continue
+		}
+		for j := range want[i].Tags {
+			if *have[i].Tags[j] != *want[i].Tags[j] {
+				t.Errorf("field %d: have tag %+v, want %+v", i, *have[i].Tags[j], *want[i].Tags[j])
+			}
+		}
+	}
+	if have := fields("Mode"); have != nil {
+		t.Errorf("expected no fields for a type that isn't a struct, got %v", have)
+	}
+}
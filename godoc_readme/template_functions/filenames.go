@@ -3,9 +3,29 @@ package template_functions
 import (
 	"fmt"
 	"path"
+	"path/filepath"
+	"strings"
 )
 
 func RelativeFilename(filepath string) (relative string) {
 	return fmt.Sprintf("./%s", path.Base(filepath))
 }
 
+// RelativeTo returns a function that, given the path of a file, returns the path of the file relative to *dir* as a markdown link destination
+// If *dir* is empty, the file is assumed to be in the same directory and the result is the same as [RelativeFilename]
+func RelativeTo(dir string) func(string) string {
+	return func(file_path string) string {
+		if dir == "" {
+			return RelativeFilename(file_path)
+		}
+		relative, err := filepath.Rel(dir, file_path)
+		if err != nil {
+			return RelativeFilename(file_path)
+		}
+		relative = filepath.ToSlash(relative)
+		if !strings.HasPrefix(relative, "../") {
+			relative = "./" + relative
+		}
+		return relative
+	}
+}
@@ -29,3 +29,23 @@ func TestRelativeFilename(t *testing.T) {
 		t.Errorf("expected %q but got %q", want, have)
 	}
 }
+
+func TestRelativeTo(t *testing.T) {
+	have := RelativeTo("/module/pkg")("/module/pkg/main.go")
+	want := "./main.go"
+	if have != want {
+		t.Errorf("expected %q but got %q", want, have)
+	}
+
+	have = RelativeTo("/module/docs/pkg")("/module/pkg/main.go")
+	want = "../../pkg/main.go"
+	if have != want {
+		t.Errorf("expected %q but got %q", want, have)
+	}
+
+	have = RelativeTo("")("/module/pkg/main.go")
+	want = "./main.go"
+	if have != want {
+		t.Errorf("expected %q but got %q", want, have)
+	}
+}
@@ -1,6 +1,11 @@
 package template_functions
 
+import "fmt"
 
+// Flags are the sections of the README that are skipped, they're read by the `flags` template function
+//
+// Deprecated: the sections are selected with the RenderFlag of the godoc_readme package and the `render` template function, i.e. `{{ if render "types" }}`.
+// Flags is only kept so the templates that use `{{ if flags "ShowTypes" }}` keep working.
 type Flags struct {
 
 	SkipImports bool
@@ -15,28 +20,34 @@ type Flags struct {
 	SkipAll bool
 }
 
-func GetFlag(flags Flags) func(string ) bool {
-	return func(flag_name string) bool {
+// GetFlag returns the `flags` template function, which reports whether a section is shown, i.e. `{{ if flags "ShowTypes" }}`
+// It returns an error for an unknown flag name
+//
+// Deprecated: use the `render` template function instead, i.e. `{{ if render "types" }}`.
+func GetFlag(flags Flags) func(string) (bool, error) {
+	return func(flag_name string) (bool, error) {
 		switch flag_name {
 			case "ShowImports":
-				return !flags.SkipImports
+				return !flags.SkipImports, nil
 			case "ShowExamples":
-				return !flags.SkipExamples
+				return !flags.SkipExamples, nil
 			case "ShowVars":
-				return !flags.SkipVars
+				return !flags.SkipVars, nil
 			case "ShowTypes":
-				return !flags.SkipTypes
+				return !flags.SkipTypes, nil
 			case "ShowFuncs":
-				return !flags.SkipFuncs
+				return !flags.SkipFuncs, nil
+			case "ShowMethods":
+				return !flags.SkipMethods, nil
 			case "ShowFilenames":
-				return !flags.SkipFilenames
+				return !flags.SkipFilenames, nil
 			case "ShowConsts": 
-				return !flags.SkipConsts
+				return !flags.SkipConsts, nil
 			case "ShowEmpty": 
-				return flags.SkipEmpty
+				return flags.SkipEmpty, nil
 			case "ShowAll": 
-				return !flags.SkipAll
+				return !flags.SkipAll, nil
 			}
-		panic("Invalid flag name " + flag_name)
+		return false, fmt.Errorf("invalid flag name %q", flag_name)
 	}
-}
\ No newline at end of file
+}
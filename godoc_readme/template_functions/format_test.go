@@ -7,10 +7,10 @@ import (
 )
 
 var godoc_readme_badge_text = "![godoc-readme badge](https://img.shields.io/badge/generated%20by%20godoc--readme-00ADD8?style=plastic&logoSize=large&logo=Go&logoColor=00ADD8&labelColor=FFFFFF)\n"
-func TestDocStringReplaceAllLeadingTabs(t *testing.T) {
-	//Test that it only replaces leading tabs
+func TestDocStringCodeBlock(t *testing.T) {
+	//Test that indented lines are rendered as a fenced code block
 	have := DocString("This is a test\n\twith a non-leading tab")
-	want := "This is a test\n    with a non-leading tab"
+	want := "This is a test\n\n```\nwith a non-leading tab\n```\n"
 	if have != want {
 		dmp := diffmatchpatch.New()
 		diffs := dmp.DiffMain(have, want, true)
@@ -27,4 +27,25 @@ func TestFormatTabs(t *testing.T) {
 		diffs := dmp.DiffMain(have, want, true)
 		t.Errorf("expected %q but got %q\nDiffs:\n%s", want, have, dmp.DiffPrettyText(diffs))
 	}
-}
\ No newline at end of file
+}
+
+func TestDocStringMarkdown(t *testing.T) {
+	have := DocStringWith(DocOptions{HeadingLevel: 3})("Intro with **bold** text\n| a | b |\n|---|---|\n\n# Usage\n\nNot a heading\n\nText\n\n  - first\n  - second\n\n```go\n\n\tcode()\n\n```\n")
+	want := "Intro with **bold** text\n| a | b |\n|---|---|\n\n### Usage\n\nNot a heading\n\nText\n\n- first\n- second\n\n```go\n\ncode()\n\n```\n"
+	if have != want {
+		dmp := diffmatchpatch.New()
+		diffs := dmp.DiffMain(have, want, true)
+		t.Errorf("expected %q but got %q\nDiffs:\n%s", want, have, dmp.DiffPrettyText(diffs))
+	}
+}
+
+func TestDocStringContinuations(t *testing.T) {
+	// Indented text that continues a footnote or a markdown list item is kept as markdown instead of being fenced
+	have := DocString("- [x] Lists\n  - [x] Nested Lists\n\n- [ ] Next\n\n[^1]: A footnote.\n[^2]: A multiline footnote.\n\n\tThis is a second line.\n")
+	want := "- [x] Lists\n  - [x] Nested Lists\n\n- [ ] Next\n\n[^1]: A footnote.\n[^2]: A multiline footnote.\n\n    This is a second line.\n"
+	if have != want {
+		dmp := diffmatchpatch.New()
+		diffs := dmp.DiffMain(have, want, true)
+		t.Errorf("expected %q but got %q\nDiffs:\n%s", want, have, dmp.DiffPrettyText(diffs))
+	}
+}
@@ -0,0 +1,28 @@
+package template_functions
+
+import (
+	"strings"
+	"unicode"
+)
+
+// HeadingSlug returns the anchor github generates for a markdown heading, i.e. `func-newreadme` for `## func NewReadme`
+// The text is lower cased, spaces are replaced with `-` and any other punctuation is removed
+func HeadingSlug(heading string) string {
+	var slug strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(heading)) {
+		switch {
+		case r == ' ':
+			slug.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			slug.WriteRune(r)
+		}
+	}
+	return slug.String()
+}
+
+// Anchor returns the id of the `<a id="...">` anchor the templates add to the heading of a symbol, its qualified name, i.e. `Readme.Generate` for the `Generate` method of `Readme`
+// Unlike the heading slugs, the ids don't change when a heading is reworded or another symbol with the same heading is added.
+// Usage: `[Generate](#{{ anchor "Readme" "Generate" }})`
+func Anchor(names ...string) string {
+	return strings.Join(names, ".")
+}
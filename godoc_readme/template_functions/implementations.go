@@ -0,0 +1,161 @@
+package template_functions
+
+import (
+	"go/ast"
+	"go/doc/comment"
+	"go/types"
+	"sort"
+)
+
+// ImplementationsOptions configures the implementation matrix returned by [Implementations]
+type ImplementationsOptions struct {
+	// Interfaces are the interfaces the types of the package are checked against, usually the exported interfaces of the module
+	Interfaces []*types.TypeName
+	// Types are the concrete types the interfaces of the package are checked against, usually the exported types of the module
+	Types []*types.TypeName
+	// LinkURL returns the URL of a doc link to a type, a link with an empty URL isn't linked
+	LinkURL func(link *comment.DocLink) string
+}
+
+// ImplementationMatrix lists the implementations of the interfaces of a package and the interfaces its types satisfy
+type ImplementationMatrix struct {
+	// Interfaces are the exported interfaces of the package with the types that implement them
+	Interfaces []*InterfaceImplementations
+	// Types are the exported types of the package with the interfaces they satisfy, types that don't satisfy any interface are left out
+	Types []*TypeInterfaces
+}
+
+// InterfaceImplementations is an interface and the concrete types that implement it
+type InterfaceImplementations struct {
+	Name  string
+	URL   string
+	Types []*TypeEntry
+}
+
+// TypeInterfaces is a type and the interfaces it satisfies
+type TypeInterfaces struct {
+	Name       string
+	URL        string
+	Interfaces []*InterfaceEntry
+}
+
+// TypeEntry is a type that implements an interface
+type TypeEntry struct {
+	// Name is the name of the type, qualified by its package name if it's declared in another package, i.e. `bytes.Buffer`
+	Name string
+	URL  string
+	// Pointer is true if only `*T` implements the interface
+	Pointer bool
+}
+
+/*
+Implementations returns a function that returns the implementation matrix of the package from its type information:
+the exported interfaces of the package alongside the types of the options that implement them, and
+the exported types of the package alongside the interfaces of the options they satisfy.
+It returns nil if the package has neither.
+Usage: `{{ with $matrix := implementations }}{{ range $matrix.Interfaces }}...{{ end }}{{ end }}`
+*/
+func Implementations(pkg *types.Package, options ImplementationsOptions) func() *ImplementationMatrix {
+	var qualifier = package_name_qualifier(pkg)
+	var link_url = func(type_object *types.TypeName) string {
+		if options.LinkURL == nil {
+			return ""
+		}
+		return options.LinkURL(&comment.DocLink{ImportPath: type_object.Pkg().Path(), Name: type_object.Name()})
+	}
+	return func() *ImplementationMatrix {
+		if pkg == nil {
+			return nil
+		}
+		var matrix = &ImplementationMatrix{}
+		for _, iface := range Interfaces(pkg) {
+			var implementations = &InterfaceImplementations{Name: iface.Name(), URL: link_url(iface)}
+			for _, type_object := range options.Types {
+				if implemented, pointer := implements(type_object, iface); implemented {
+					implementations.Types = append(implementations.Types, &TypeEntry{
+						Name:    types.TypeString(type_object.Type(), qualifier),
+						URL:     link_url(type_object),
+						Pointer: pointer,
+					})
+				}
+			}
+			matrix.Interfaces = append(matrix.Interfaces, implementations)
+		}
+		for _, type_object := range ConcreteTypes(pkg) {
+			var type_interfaces = &TypeInterfaces{Name: type_object.Name(), URL: link_url(type_object)}
+			for _, iface := range options.Interfaces {
+				if implemented, pointer := implements(type_object, iface); implemented {
+					type_interfaces.Interfaces = append(type_interfaces.Interfaces, &InterfaceEntry{
+						Name:    types.TypeString(iface.Type(), qualifier),
+						URL:     link_url(iface),
+						Pointer: pointer,
+					})
+				}
+			}
+			if len(type_interfaces.Interfaces) > 0 {
+				matrix.Types = append(matrix.Types, type_interfaces)
+			}
+		}
+		if len(matrix.Interfaces) == 0 && len(matrix.Types) == 0 {
+			return nil
+		}
+		return matrix
+	}
+}
+
+// ConcreteTypes returns the exported types declared in the packages that aren't interfaces, sorted by package path and name
+// Aliases and generic types are skipped, an uninstantiated generic type can't be checked against an interface
+func ConcreteTypes(pkgs ...*types.Package) (concrete_types []*types.TypeName) {
+	for _, pkg := range pkgs {
+		if pkg == nil {
+			continue
+		}
+		for _, name := range pkg.Scope().Names() {
+			type_object, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+			if !ok || !ast.IsExported(name) || type_object.IsAlias() || types.IsInterface(type_object.Type()) {
+				continue
+			}
+			if named, ok := type_object.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+				continue
+			}
+			concrete_types = append(concrete_types, type_object)
+		}
+	}
+	sort_type_names(concrete_types)
+	return
+}
+
+// implements reports whether `T` or `*T` implements the interface, *pointer* is true if only `*T` does
+func implements(type_object *types.TypeName, iface *types.TypeName) (implemented bool, pointer bool) {
+	var underlying, ok = iface.Type().Underlying().(*types.Interface)
+	if !ok || type_object == iface {
+		return
+	}
+	if types.Implements(type_object.Type(), underlying) {
+		return true, false
+	}
+	if types.Implements(types.NewPointer(type_object.Type()), underlying) {
+		return true, true
+	}
+	return
+}
+
+// package_name_qualifier qualifies the types declared outside of the package by their package name, i.e. `bytes.Buffer`
+func package_name_qualifier(pkg *types.Package) types.Qualifier {
+	return func(other *types.Package) string {
+		if pkg != nil && other.Path() == pkg.Path() {
+			return ""
+		}
+		return other.Name()
+	}
+}
+
+// sort_type_names sorts the types by package path and name
+func sort_type_names(type_names []*types.TypeName) {
+	sort.SliceStable(type_names, func(i, j int) bool {
+		if type_names[i].Pkg().Path() != type_names[j].Pkg().Path() {
+			return type_names[i].Pkg().Path() < type_names[j].Pkg().Path()
+		}
+		return type_names[i].Name() < type_names[j].Name()
+	})
+}
@@ -0,0 +1,48 @@
+package template_functions
+
+import (
+	"go/types"
+	"testing"
+)
+
+func TestImplementations(t *testing.T) {
+	var pkg = check_package(t, `package p
+
+type Shape interface{ Area() float64 }
+
+type Named interface{ Name() string }
+
+type Square struct{}
+
+func (*Square) Area() float64 { return 0 }
+
+type Circle struct{}
+
+func (Circle) Area() float64 { return 0 }
+
+type Point struct{}
+`)
+	matrix := Implementations(pkg, ImplementationsOptions{
+		Interfaces: Interfaces(pkg),
+		Types:      ConcreteTypes(pkg),
+	})()
+	if matrix == nil || len(matrix.Interfaces) != 2 {
+		t.Fatalf("expected both interfaces in the matrix, got %+v", matrix)
+	}
+	if named := matrix.Interfaces[0]; named.Name != "Named" || len(named.Types) != 0 {
+		t.Errorf("expected Named to have no implementations, got %+v", named)
+	}
+	shape := matrix.Interfaces[1]
+	if len(shape.Types) != 2 || *shape.Types[0] != (TypeEntry{Name: "Circle"}) || *shape.Types[1] != (TypeEntry{Name: "Square", Pointer: true}) {
+		t.Errorf("expected Circle and *Square to implement Shape, got %+v %+v", shape.Types[0], shape.Types[1])
+	}
+	if len(matrix.Types) != 2 || matrix.Types[0].Name != "Circle" || matrix.Types[1].Name != "Square" {
+		t.Errorf("expected only the types implementing an interface, got %+v", matrix.Types)
+	}
+	if have := ConcreteTypes(pkg); len(have) != 3 {
+		t.Errorf("expected the 3 concrete types, got %v", have)
+	}
+	if have := Implementations(types.NewPackage("example.com/empty", "empty"), ImplementationsOptions{})(); have != nil {
+		t.Errorf("expected no matrix for a package without types, got %+v", have)
+	}
+}
@@ -0,0 +1,148 @@
+package template_functions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// ImportGraphOptions configures the import graphs rendered by [ImportGraph]
+type ImportGraphOptions struct {
+	// Module is the path of the module, the packages in it are drawn as their own nodes, i.e. `github.com/dubbikins/godoc-readme`
+	Module string
+	// Collapse draws the standard library and third-party imports as two grouped nodes instead of a node for each package
+	Collapse bool
+}
+
+// The kinds of the nodes of an import graph, in the order they're drawn
+const (
+	module_import = iota
+	std_import
+	third_party_import
+)
+
+// The IDs and labels of the grouped nodes of a collapsed import graph
+var collapsed_import_nodes = map[int][2]string{
+	std_import:         {"std", "standard library"},
+	third_party_import: {"third_party", "third-party"},
+}
+
+/*
+ImportGraph returns a function that renders a mermaid graph of the imports of the packages of the module, from the `Imports` of the packages.
+The packages should be the non-test variants of the packages, so the imports of the `_test.go` files aren't drawn.
+Given the import paths of some of the packages, i.e. `{{ import_graph .Pkg.PkgPath }}`, the graph shows these packages, the packages they import
+and the packages of the module that import them. Without any import path, i.e. `{{ import_graph }}`, it shows every package and which packages of the module import which.
+
+The packages of the module are labeled with their path relative to the module, the standard library and third-party imports are drawn as rounded nodes
+and grouped into a `standard library` and a `third-party` node with the `Collapse` option. The graph is empty if there are no imports to draw.
+*/
+func ImportGraph(pkgs []*packages.Package, options ImportGraphOptions) func(import_paths ...string) string {
+	var imports = map[string][]string{}
+	for _, pkg := range pkgs {
+		imports[pkg.PkgPath] = sorted_imports(pkg)
+	}
+	return func(import_paths ...string) string {
+		var edges = map[[2]string]bool{}
+		var nodes = map[string]int{}
+		var add_edge = func(from string, to string) {
+			nodes[from] = module_import
+			var kind = import_kind(to, options.Module)
+			if options.Collapse && kind != module_import {
+				to = collapsed_import_nodes[kind][0]
+			}
+			nodes[to] = kind
+			edges[[2]string{from, to}] = true
+		}
+		var module_wide = len(import_paths) == 0
+		for _, pkg := range pkgs {
+			var focused = module_wide || contains(import_paths, pkg.PkgPath)
+			if focused {
+				nodes[pkg.PkgPath] = module_import
+			}
+			for _, import_path := range imports[pkg.PkgPath] {
+				switch {
+				case module_wide && (import_kind(import_path, options.Module) == module_import || options.Collapse):
+				case !module_wide && (focused || contains(import_paths, import_path)):
+				default:
+					continue
+				}
+				add_edge(pkg.PkgPath, import_path)
+			}
+		}
+		if len(edges) == 0 {
+			return ""
+		}
+		var node_names = make([]string, 0, len(nodes))
+		for name := range nodes {
+			node_names = append(node_names, name)
+		}
+		sort.Slice(node_names, func(i, j int) bool {
+			if nodes[node_names[i]] != nodes[node_names[j]] {
+				return nodes[node_names[i]] < nodes[node_names[j]]
+			}
+			return node_names[i] < node_names[j]
+		})
+		var ids = map[string]string{}
+		var buf strings.Builder
+		buf.WriteString("```mermaid\ngraph LR\n")
+		for i, name := range node_names {
+			ids[name] = fmt.Sprintf("p%d", i)
+			switch kind := nodes[name]; {
+			case kind == module_import:
+				buf.WriteString(fmt.Sprintf("    %s[\"%s\"]\n", ids[name], module_package_label(name, options.Module)))
+			case options.Collapse:
+				ids[name] = collapsed_import_nodes[kind][0]
+				buf.WriteString(fmt.Sprintf("    %s([\"%s\"])\n", ids[name], collapsed_import_nodes[kind][1]))
+			default:
+				buf.WriteString(fmt.Sprintf("    %s([\"%s\"])\n", ids[name], name))
+			}
+		}
+		var sorted_edges = make([][2]string, 0, len(edges))
+		for edge := range edges {
+			sorted_edges = append(sorted_edges, edge)
+		}
+		sort.Slice(sorted_edges, func(i, j int) bool {
+			if sorted_edges[i][0] != sorted_edges[j][0] {
+				return sorted_edges[i][0] < sorted_edges[j][0]
+			}
+			return sorted_edges[i][1] < sorted_edges[j][1]
+		})
+		for _, edge := range sorted_edges {
+			buf.WriteString(fmt.Sprintf("    %s --> %s\n", ids[edge[0]], ids[edge[1]]))
+		}
+		buf.WriteString("```\n")
+		return buf.String()
+	}
+}
+
+// sorted_imports returns the sorted import paths of the package
+func sorted_imports(pkg *packages.Package) (import_paths []string) {
+	for import_path := range pkg.Imports {
+		if import_path != "C" {
+			import_paths = append(import_paths, import_path)
+		}
+	}
+	sort.Strings(import_paths)
+	return
+}
+
+// import_kind tells the packages of the module apart from the standard library, whose import paths don't start with a domain, and third-party packages
+func import_kind(import_path string, module string) int {
+	if module != "" && (import_path == module || strings.HasPrefix(import_path, module+"/")) {
+		return module_import
+	}
+	if first, _, _ := strings.Cut(import_path, "/"); !strings.Contains(first, ".") {
+		return std_import
+	}
+	return third_party_import
+}
+
+// module_package_label returns the path of a package relative to the module, or the module path for the package in the module root
+func module_package_label(import_path string, module string) string {
+	if label := strings.TrimPrefix(strings.TrimPrefix(import_path, module), "/"); label != "" && module != "" {
+		return label
+	}
+	return import_path
+}
@@ -0,0 +1,60 @@
+package template_functions
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func graph_package(pkg_path string, import_paths ...string) *packages.Package {
+	var pkg = &packages.Package{PkgPath: pkg_path, Imports: map[string]*packages.Package{}}
+	for _, import_path := range import_paths {
+		pkg.Imports[import_path] = &packages.Package{PkgPath: import_path}
+	}
+	return pkg
+}
+
+func TestImportGraph(t *testing.T) {
+	var pkgs = []*packages.Package{
+		graph_package("example.com/m", "fmt", "example.com/m/a"),
+		graph_package("example.com/m/a", "strings", "example.com/m/b", "golang.org/x/tools/go/packages"),
+		graph_package("example.com/m/b", "os"),
+	}
+	var graph = ImportGraph(pkgs, ImportGraphOptions{Module: "example.com/m"})
+	var focused = graph("example.com/m/a")
+	for _, want := range []string{
+		"```mermaid\ngraph LR\n",
+		`p0["example.com/m"]`,
+		`p1["a"]`,
+		`p2["b"]`,
+		`p3(["strings"])`,
+		`p4(["golang.org/x/tools/go/packages"])`,
+		"p0 --> p1\n",
+		"p1 --> p2\n",
+		"p1 --> p3\n",
+		"p1 --> p4\n",
+	} {
+		if !strings.Contains(focused, want) {
+			t.Errorf("expected the graph of a to contain %q, got:\n%s", want, focused)
+		}
+	}
+	for _, unwanted := range []string{"fmt", "os"} {
+		if strings.Contains(focused, `"`+unwanted+`"`) {
+			t.Errorf("expected the graph of a not to contain %q, got:\n%s", unwanted, focused)
+		}
+	}
+	var module_wide = graph()
+	if strings.Contains(module_wide, "strings") || !strings.Contains(module_wide, "p1 --> p2\n") {
+		t.Errorf("expected only the imports between the packages of the module, got:\n%s", module_wide)
+	}
+	var collapsed = ImportGraph(pkgs, ImportGraphOptions{Module: "example.com/m", Collapse: true})()
+	for _, want := range []string{`std(["standard library"])`, `third_party(["third-party"])`, "p1 --> std\n", "p1 --> third_party\n", "p2 --> std\n"} {
+		if !strings.Contains(collapsed, want) {
+			t.Errorf("expected the collapsed graph to contain %q, got:\n%s", want, collapsed)
+		}
+	}
+	if have := ImportGraph(nil, ImportGraphOptions{})(); have != "" {
+		t.Errorf("expected no graph without packages, got %q", have)
+	}
+}
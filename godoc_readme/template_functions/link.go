@@ -4,21 +4,83 @@ import (
 	"bytes"
 	"fmt"
 	"go/ast"
-	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"golang.org/x/tools/go/packages"
 )
 
+// LinkOptions configures the destination of the links rendered by [LinkWith]
+type LinkOptions struct {
+	// Dir is the directory of the README the link is rendered in, links are relative to it
+	// If empty, the README is assumed to be in the same directory as the source file
+	Dir string
+	// SourceURL, if set, is the URL template of the links instead of a relative path, i.e. `https://github.com/{owner}/{repo}/blob/{ref}/{path}#L{start}-L{end}`
+	// `{path}` is the source file relative to the `RepoDir`, `{start}` and `{end}` are its first and last line
+	SourceURL string
+	// RepoDir is the root of the repository that `{path}` is relative to, the module root if the module is the whole repository
+	RepoDir string
+	// Owner, Repo and Ref replace the `{owner}`, `{repo}` and `{ref}` placeholders of the `SourceURL`
+	Owner string
+	Repo  string
+	Ref   string
+}
+
+// source_url returns the `SourceURL` with its placeholders replaced for the lines of the file
+func (options LinkOptions) source_url(source_url string, file_name string, start_ln, end_ln int) string {
+	var rel_file_name, err = filepath.Rel(options.RepoDir, file_name)
+	if err != nil || options.RepoDir == "" {
+		rel_file_name = filepath.Base(file_name)
+	}
+	return strings.NewReplacer(
+		"{owner}", options.Owner,
+		"{repo}", options.Repo,
+		"{ref}", options.Ref,
+		"{path}", filepath.ToSlash(rel_file_name),
+		"{start}", strconv.Itoa(start_ln),
+		"{end}", strconv.Itoa(end_ln),
+	).Replace(source_url)
+}
+
 // Link returns a markdown link to the  location of the ast.Node in a package
 // Can be called in a template by using the `fmt` function `{{ link . }}` where `.` is a type that implements `*ast.Node`
 func Link(pkg *packages.Package) func(string, ast.Node) string {
+	return LinkWith(pkg, LinkOptions{})
+}
+
+// LinkWith returns a markdown link to the location of the ast.Node in a package, relative to the README directory in the options
+// Use it instead of [Link] when the README isn't written to the package directory, or with the `SourceURL` option to link to the hosted source
+func LinkWith(pkg *packages.Package, options LinkOptions) func(string, ast.Node) string {
+	var relative_path = RelativeTo(options.Dir)
 	return func(title string, node ast.Node) string {
 		var buf = bytes.NewBuffer(nil)
 		file := pkg.Fset.File(node.Pos())
 		start_ln := file.Line(node.Pos())
 		end_ln := file.Line(node.End())
-		buf.WriteString(fmt.Sprintf("[%s](./%s#L%d-L%d)", title, path.Base(file.Name()), start_ln, end_ln))
+		if options.SourceURL != "" {
+			buf.WriteString(fmt.Sprintf("[%s](%s)", title, options.source_url(options.SourceURL, file.Name(), start_ln, end_ln)))
+			return buf.String()
+		}
+		buf.WriteString(fmt.Sprintf("[%s](%s#L%d-L%d)", title, relative_path(file.Name()), start_ln, end_ln))
 
 		return buf.String()
 	}
 }
+
+// FileURL returns a function that returns the link destination of a source file, the `SourceURL` of the options without its line fragment, i.e. `#L{start}-L{end}`,
+// or the path of the file relative to the README directory if there's no `SourceURL`
+// Usage: `[{{ filename . }}]({{ file_url . }})`
+func FileURL(options LinkOptions) func(string) string {
+	var relative_path = RelativeTo(options.Dir)
+	var source_url = options.SourceURL
+	if before, fragment, found := strings.Cut(source_url, "#"); found && (strings.Contains(fragment, "{start}") || strings.Contains(fragment, "{end}")) {
+		source_url = before
+	}
+	return func(file_name string) string {
+		if source_url == "" {
+			return relative_path(file_name)
+		}
+		return options.source_url(source_url, file_name, 0, 0)
+	}
+}
@@ -0,0 +1,57 @@
+package template_functions
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestLinkSourceURL(t *testing.T) {
+	var fset = token.NewFileSet()
+	var module_dir = filepath.FromSlash("/src/mod")
+	file, err := parser.ParseFile(fset, filepath.Join(module_dir, "pkg", "a.go"), "package pkg\n\nfunc A() {\n}\n", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pkg = &packages.Package{Fset: fset}
+	var decl = file.Decls[0].(*ast.FuncDecl)
+	link := LinkWith(pkg, LinkOptions{
+		Dir:       filepath.Join(module_dir, "docs"),
+		SourceURL: "https://github.com/{owner}/{repo}/blob/{ref}/{path}#L{start}-L{end}",
+		RepoDir:   module_dir,
+		Owner:     "owner",
+		Repo:      "repo",
+		Ref:       "v1.0.0",
+	})
+	want := "[func A](https://github.com/owner/repo/blob/v1.0.0/pkg/a.go#L3-L4)"
+	if have := link("func A", decl); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+	want = "[func A](../pkg/a.go#L3-L4)"
+	if have := LinkWith(pkg, LinkOptions{Dir: filepath.Join(module_dir, "docs")})("func A", decl); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestFileURL(t *testing.T) {
+	var repo_dir = filepath.FromSlash("/src/repo")
+	var file_name = filepath.Join(repo_dir, "mod", "pkg", "a.go")
+	var options = LinkOptions{
+		Dir:       filepath.Join(repo_dir, "mod", "pkg"),
+		SourceURL: "https://github.com/{owner}/{repo}/blob/{ref}/{path}#L{start}-L{end}",
+		RepoDir:   repo_dir,
+		Owner:     "owner",
+		Repo:      "repo",
+		Ref:       "v1.0.0",
+	}
+	if have, want := FileURL(options)(file_name), "https://github.com/owner/repo/blob/v1.0.0/mod/pkg/a.go"; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+	if have, want := FileURL(LinkOptions{Dir: options.Dir})(file_name), "./a.go"; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
@@ -0,0 +1,210 @@
+package template_functions
+
+import (
+	"fmt"
+	"go/doc/comment"
+	"regexp"
+	"strings"
+)
+
+// The in-line alerts of a package doc, i.e. `NOTE(target): text`, that are rendered as github markdown alerts
+var inline_alerts_pattern = regexp.MustCompile(`(?m:^(NOTE|WARNING|IMPORTANT|CAUTION|TIP)\(([a-zA-Z][a-zA-Z0-9_]*)\):(.*)$)`)
+
+// A markdown list item at the end of a paragraph, i.e. `- item` or `1. item`
+var markdown_list_item_pattern = regexp.MustCompile(`(?m:^\s*([-*+]|\d+\.) .*\z)`)
+
+// A markdown footnote definition at the end of a paragraph, i.e. `[^1]: text`
+var markdown_footnote_pattern = regexp.MustCompile(`(?m:^\[\^[^\]]+\]:.*\z)`)
+
+/*
+MarkdownPrinter prints a doc comment parsed with [comment.Parser] as github markdown.
+
+Unlike [comment.Printer.Markdown], text isn't escaped, so the markdown that is written in doc comments, i.e. tables, emphasis or task lists, is kept as-is.
+Fenced code blocks aren't godoc, so they should be split off before the doc comment is parsed, like the `doc` and `pkg_doc` template functions do.
+The godoc syntax is converted to its markdown equivalent:
+
+| Godoc | Markdown |
+| --- | --- |
+| `# Heading` | A heading of the `HeadingLevel`, i.e. `## Heading` |
+| An indented code block | A fenced code block, or indented text if it continues the markdown list item or footnote before it |
+| A list | A markdown list, nested under the markdown list item before it if there is one |
+| `[Type]`, `[pkg.Func]`, `[Type.Method]` | A markdown link to the URL returned by `LinkURL` |
+| `[text]: url` link definitions | Markdown links |
+| `NOTE(target): text` | A github markdown alert if `Alerts` is set |
+*/
+type MarkdownPrinter struct {
+	// HeadingLevel is the markdown heading level of a `# Heading` in the doc comment, between 1 and 6
+	HeadingLevel int
+	// LinkURL returns the URL of a doc link, a doc link with an empty URL is printed as-is
+	LinkURL func(link *comment.DocLink) string
+	// Alerts converts the in-line alerts of a package doc into github markdown alerts
+	Alerts bool
+}
+
+// markdown_printer holds the state of a single call to [MarkdownPrinter.Markdown]
+type markdown_printer struct {
+	*MarkdownPrinter
+	out strings.Builder
+}
+
+// Markdown returns the markdown of the doc comment
+func (printer *MarkdownPrinter) Markdown(doc *comment.Doc) string {
+	var p = &markdown_printer{MarkdownPrinter: printer}
+	var previous comment.Block
+	for _, block := range doc.Content {
+		if previous != nil {
+			// A nested list stays tight, a blank line would make the markdown list before it loose
+			if _, ok := block.(*comment.List); ok && continues_list_item(previous) {
+				p.out.WriteString("\n")
+			} else {
+				p.out.WriteString("\n\n")
+			}
+		}
+		p.block(block, previous)
+		previous = block
+	}
+	for _, link_def := range doc.Links {
+		if !link_def.Used {
+			p.out.WriteString(fmt.Sprintf("\n\n[%s]: %s", link_def.Text, link_def.URL))
+		}
+	}
+	if p.out.Len() > 0 {
+		p.out.WriteString("\n")
+	}
+	return p.out.String()
+}
+
+func (p *markdown_printer) block(block comment.Block, previous comment.Block) {
+	switch block := block.(type) {
+	case *comment.Paragraph:
+		var start = p.out.Len()
+		p.text(block.Text)
+		if p.Alerts {
+			var paragraph = p.out.String()[start:]
+			p.reset(start)
+			p.out.WriteString(inline_alerts_pattern.ReplaceAllString(paragraph, "> [!$1]\n>$3"))
+		}
+	case *comment.Heading:
+		p.out.WriteString(strings.Repeat("#", min(max(p.HeadingLevel, 1), 6)) + " ")
+		p.text(block.Text)
+	case *comment.Code:
+		if continues_list_item(previous) || continues_footnote(previous) {
+			// The indented text is a continuation of the markdown before it, i.e. the second line of a footnote, so it's kept indented rather than fenced
+			var lines = strings.Split(strings.TrimSuffix(block.Text, "\n"), "\n")
+			for i, line := range lines {
+				if line != "" {
+					lines[i] = "    " + line
+				}
+			}
+			p.out.WriteString(strings.Join(lines, "\n"))
+			return
+		}
+		p.out.WriteString("```\n")
+		p.out.WriteString(block.Text)
+		p.out.WriteString("```")
+	case *comment.List:
+		var indent string
+		if continues_list_item(previous) {
+			indent = "  "
+		}
+		for i, item := range block.Items {
+			if i > 0 {
+				p.out.WriteString("\n")
+				if block.BlankBetween() {
+					p.out.WriteString("\n")
+				}
+			}
+			var marker = "- "
+			if item.Number != "" {
+				marker = item.Number + ". "
+			}
+			p.out.WriteString(indent + marker)
+			var item_start = p.out.Len()
+			for j, item_block := range item.Content {
+				if j > 0 {
+					p.out.WriteString("\n\n")
+				}
+				p.block(item_block, nil)
+			}
+			// Indent the continuation lines of the item under its marker
+			var item_text = p.out.String()[item_start:]
+			p.reset(item_start)
+			p.out.WriteString(strings.ReplaceAll(item_text, "\n", "\n"+indent+strings.Repeat(" ", len(marker))))
+		}
+	}
+}
+
+// continues_list_item reports whether the block is a paragraph that ends with a markdown list item
+func continues_list_item(block comment.Block) bool {
+	paragraph, ok := block.(*comment.Paragraph)
+	return ok && markdown_list_item_pattern.MatchString(plain_text(paragraph.Text))
+}
+
+// continues_footnote reports whether the block is a paragraph that ends with a markdown footnote definition
+func continues_footnote(block comment.Block) bool {
+	paragraph, ok := block.(*comment.Paragraph)
+	return ok && markdown_footnote_pattern.MatchString(plain_text(paragraph.Text))
+}
+
+// reset truncates the printed markdown to *n* bytes
+func (p *markdown_printer) reset(n int) {
+	var printed = p.out.String()[:n]
+	p.out.Reset()
+	p.out.WriteString(printed)
+}
+
+func (p *markdown_printer) text(text []comment.Text) {
+	for i, t := range text {
+		switch t := t.(type) {
+		case comment.Plain:
+			p.out.WriteString(string(t))
+		case comment.Italic:
+			p.out.WriteString("_" + string(t) + "_")
+		case *comment.Link:
+			if t.Auto {
+				p.text(t.Text)
+				continue
+			}
+			p.out.WriteString("[")
+			p.text(t.Text)
+			p.out.WriteString("](" + t.URL + ")")
+		case *comment.DocLink:
+			var url string
+			// A doc link followed by `(` is a markdown link, i.e. `[Type](./file.go)`, and brackets in code aren't links
+			var markdown_link = i+1 < len(text) && strings.HasPrefix(plain_text(text[i+1:i+2]), "(")
+			if p.LinkURL != nil && !markdown_link && !p.in_inline_code() {
+				url = p.LinkURL(t)
+			}
+			p.out.WriteString("[")
+			p.text(t.Text)
+			p.out.WriteString("]")
+			if url != "" {
+				p.out.WriteString("(" + url + ")")
+			}
+		}
+	}
+}
+
+// in_inline_code reports whether the current line has an unclosed inline code span
+func (p *markdown_printer) in_inline_code() bool {
+	var printed = p.out.String()
+	return strings.Count(printed[strings.LastIndex(printed, "\n")+1:], "`")%2 == 1
+}
+
+// plain_text returns the text without any markup
+func plain_text(text []comment.Text) string {
+	var buf strings.Builder
+	for _, t := range text {
+		switch t := t.(type) {
+		case comment.Plain:
+			buf.WriteString(string(t))
+		case comment.Italic:
+			buf.WriteString(string(t))
+		case *comment.Link:
+			buf.WriteString(plain_text(t.Text))
+		case *comment.DocLink:
+			buf.WriteString("[" + plain_text(t.Text) + "]")
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,161 @@
+package template_functions
+
+import (
+	"go/ast"
+	"go/doc/comment"
+	"go/types"
+	"sort"
+)
+
+// MethodSetOptions configures the method sets returned by [MethodSets]
+type MethodSetOptions struct {
+	// Interfaces are the interfaces the types are checked against, usually the exported interfaces of the module
+	Interfaces []*types.TypeName
+	// LinkURL returns the URL of a doc link to a type or method, a link with an empty URL isn't linked
+	LinkURL func(link *comment.DocLink) string
+}
+
+// MethodSet is the complete method set of a type, including the methods promoted from its embedded fields, and the interfaces it satisfies
+type MethodSet struct {
+	// Type is the name of the type, i.e. `Readme`
+	Type string
+	// Methods are the exported methods of `*T`, sorted by name
+	Methods []*MethodSetEntry
+	// Implements are the interfaces that `T` or `*T` satisfies
+	Implements []*InterfaceEntry
+}
+
+// MethodSetEntry is a method in the method set of a type
+type MethodSetEntry struct {
+	Name string
+	URL  string
+	// Pointer is true if the method is only in the method set of `*T`, not `T`
+	Pointer bool
+	// Embedded is the embedded type that declares a promoted method, i.e. `bytes.Buffer`, it's empty for the methods declared on the type itself
+	Embedded    string
+	EmbeddedURL string
+}
+
+// InterfaceEntry is an interface that a type satisfies
+type InterfaceEntry struct {
+	// Name is the name of the interface, qualified by its package name if it's declared in another package, i.e. `io.Writer`
+	Name string
+	URL  string
+	// Pointer is true if only `*T` satisfies the interface
+	Pointer bool
+}
+
+/*
+MethodSets returns a function that returns the method set of a type declared in the package, by name, from its type information.
+Unlike the methods of a [doc.Type], the method set includes the methods promoted from embedded types declared in other packages,
+tells the methods of `T` apart from the methods that are only on `*T` and lists the interfaces of the options that `T` or `*T` satisfies.
+It returns nil for an interface type, or a type without exported methods that doesn't satisfy any of the interfaces.
+Usage: `{{ with $set := method_set .Name }}{{ range $set.Methods }}...{{ end }}{{ end }}`
+*/
+func MethodSets(pkg *types.Package, options MethodSetOptions) func(type_name string) *MethodSet {
+	var qualifier = package_name_qualifier(pkg)
+	var link_url = func(link *comment.DocLink) string {
+		if options.LinkURL == nil {
+			return ""
+		}
+		return options.LinkURL(link)
+	}
+	return func(type_name string) *MethodSet {
+		if pkg == nil {
+			return nil
+		}
+		type_object, ok := pkg.Scope().Lookup(type_name).(*types.TypeName)
+		if !ok || type_object.IsAlias() || types.IsInterface(type_object.Type()) {
+			return nil
+		}
+		var method_set = &MethodSet{Type: type_name}
+		var value_methods = types.NewMethodSet(type_object.Type())
+		var pointer_methods = types.NewMethodSet(types.NewPointer(type_object.Type()))
+		for i := 0; i < pointer_methods.Len(); i++ {
+			var selection = pointer_methods.At(i)
+			var method = selection.Obj()
+			if !method.Exported() {
+				continue
+			}
+			var entry = &MethodSetEntry{
+				Name:    method.Name(),
+				Pointer: value_methods.Lookup(method.Pkg(), method.Name()) == nil,
+			}
+			var recv = receiver_type(method)
+			if recv == nil {
+				method_set.Methods = append(method_set.Methods, entry)
+				continue
+			}
+			entry.URL = link_url(&comment.DocLink{ImportPath: method.Pkg().Path(), Recv: recv.Obj().Name(), Name: method.Name()})
+			if len(selection.Index()) > 1 {
+				entry.Embedded = types.TypeString(recv.Obj().Type(), qualifier)
+				entry.EmbeddedURL = link_url(&comment.DocLink{ImportPath: recv.Obj().Pkg().Path(), Name: recv.Obj().Name()})
+			}
+			method_set.Methods = append(method_set.Methods, entry)
+		}
+		sort.Slice(method_set.Methods, func(i, j int) bool {
+			return method_set.Methods[i].Name < method_set.Methods[j].Name
+		})
+		// The behavior of types.Implements is unspecified for an uninstantiated generic type
+		if named, ok := type_object.Type().(*types.Named); !ok || named.TypeParams().Len() == 0 {
+			for _, iface := range options.Interfaces {
+				var entry = &InterfaceEntry{
+					Name: types.TypeString(iface.Type(), qualifier),
+					URL:  link_url(&comment.DocLink{ImportPath: iface.Pkg().Path(), Name: iface.Name()}),
+				}
+				var implemented bool
+				if implemented, entry.Pointer = implements(type_object, iface); !implemented {
+					continue
+				}
+				method_set.Implements = append(method_set.Implements, entry)
+			}
+		}
+		if len(method_set.Methods) == 0 && len(method_set.Implements) == 0 {
+			return nil
+		}
+		return method_set
+	}
+}
+
+// Interfaces returns the exported interfaces declared in the packages that have at least one method, sorted by package path and name
+// Constraint interfaces, i.e. `interface{ ~int }`, and empty interfaces are skipped because they don't describe behavior
+func Interfaces(pkgs ...*types.Package) (interfaces []*types.TypeName) {
+	for _, pkg := range pkgs {
+		if pkg == nil {
+			continue
+		}
+		for _, name := range pkg.Scope().Names() {
+			type_object, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+			if !ok || !ast.IsExported(name) {
+				continue
+			}
+			iface, ok := type_object.Type().Underlying().(*types.Interface)
+			if !ok || !iface.IsMethodSet() || iface.NumMethods() == 0 {
+				continue
+			}
+			if named, ok := type_object.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+				continue
+			}
+			interfaces = append(interfaces, type_object)
+		}
+	}
+	sort_type_names(interfaces)
+	return
+}
+
+// receiver_type returns the named type that declares the method, or nil for a method of an interface
+func receiver_type(method types.Object) *types.Named {
+	var signature, ok = method.Type().(*types.Signature)
+	if !ok || signature.Recv() == nil {
+		return nil
+	}
+	var recv = signature.Recv().Type()
+	if pointer, ok := recv.(*types.Pointer); ok {
+		recv = pointer.Elem()
+	}
+	named, ok := recv.(*types.Named)
+	if !ok {
+		return nil
+	}
+	return named.Origin()
+}
@@ -0,0 +1,75 @@
+package template_functions
+
+import (
+	"go/ast"
+	"go/doc/comment"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func check_package(t *testing.T, src string) *types.Package {
+	t.Helper()
+	var fset = token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := (&types.Config{}).Check("example.com/p", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg
+}
+
+func TestMethodSets(t *testing.T) {
+	var pkg = check_package(t, `package p
+
+type Shape interface{ Area() float64 }
+
+type Base struct{}
+
+func (Base) Name() string     { return "" }
+func (*Base) SetName(string)  {}
+
+type Square struct{ Base }
+
+func (*Square) Area() float64 { return 0 }
+
+type Circle struct{}
+
+func (Circle) Area() float64 { return 0 }
+`)
+	method_set := MethodSets(pkg, MethodSetOptions{
+		Interfaces: Interfaces(pkg),
+		LinkURL: func(link *comment.DocLink) string {
+			if link.Recv != "" {
+				return "#" + link.Recv + "." + link.Name
+			}
+			return "#" + link.Name
+		},
+	})
+	square := method_set("Square")
+	if square == nil || len(square.Methods) != 3 {
+		t.Fatalf("expected the promoted methods in the method set, got %+v", square)
+	}
+	for i, want := range []MethodSetEntry{
+		{Name: "Area", URL: "#Square.Area", Pointer: true},
+		{Name: "Name", URL: "#Base.Name", Embedded: "Base", EmbeddedURL: "#Base"},
+		{Name: "SetName", URL: "#Base.SetName", Pointer: true, Embedded: "Base", EmbeddedURL: "#Base"},
+	} {
+		if have := *square.Methods[i]; have != want {
+			t.Errorf("have %+v, want %+v", have, want)
+		}
+	}
+	if len(square.Implements) != 1 || square.Implements[0].Name != "Shape" || !square.Implements[0].Pointer {
+		t.Errorf("expected only *Square to implement Shape, got %+v", square.Implements)
+	}
+	if circle := method_set("Circle"); circle == nil || len(circle.Implements) != 1 || circle.Implements[0].Pointer {
+		t.Errorf("expected Circle to implement Shape, got %+v", circle)
+	}
+	if shape := method_set("Shape"); shape != nil {
+		t.Errorf("expected no method set for an interface, got %+v", shape)
+	}
+}
@@ -0,0 +1,89 @@
+package template_functions
+
+import (
+	"go/doc"
+)
+
+// TocEntry is an entry of a README's table of contents, it links to the heading with the anchor
+type TocEntry struct {
+	Title   string
+	Anchor  string
+	Entries []*TocEntry
+}
+
+// TocOptions selects the sections listed in the table of contents, they should match the sections that are rendered
+type TocOptions struct {
+	Types     bool
+	Methods   bool
+	Funcs     bool
+	Consts    bool
+	Vars      bool
+	Examples  bool
+	SkipEmpty bool
+}
+
+/*
+Toc returns a function that lists the Types (with their methods), Functions, Constants, Variables and Examples of a package as a table of contents.
+The symbols link to the `<a id="...">` anchors of the default templates, i.e. `Readme.Generate`, see [Anchor],
+and the sections link to the slugs github generates for their headings, i.e. `types` for `## Types`.
+The Examples list the package examples and the examples of the types, functions and methods that are rendered, each links to the anchor of its example, see [ExampleAnchor].
+A section without any entries, i.e. the Types when every type is skipped by SkipEmpty, isn't listed.
+Usage: `{{ range toc .Doc }}- [{{ .Title }}](#{{ .Anchor }}){{ end }}`
+*/
+func Toc(options TocOptions) func(*doc.Package) []*TocEntry {
+	var filtered_funcs = FilteredFuncs(MethodsOptions{SkipEmpty: options.SkipEmpty})
+	return func(pkg *doc.Package) (toc []*TocEntry) {
+		// The examples are listed in the order they're rendered, after the types and functions they belong to
+		var examples []*doc.Example
+		if options.Types {
+			var types = &TocEntry{Title: "Types", Anchor: HeadingSlug("Types")}
+			for _, _type := range pkg.Types {
+				if options.SkipEmpty && _type.Doc == "" {
+					continue
+				}
+				var type_entry = &TocEntry{Title: _type.Name, Anchor: Anchor(_type.Name)}
+				examples = append(examples, _type.Examples...)
+				if options.Methods {
+					for _, method := range filtered_funcs(_type.Methods) {
+						type_entry.Entries = append(type_entry.Entries, &TocEntry{
+							Title:  _type.Name + "." + method.Name,
+							Anchor: Anchor(_type.Name, method.Name),
+						})
+						examples = append(examples, method.Examples...)
+					}
+				}
+				types.Entries = append(types.Entries, type_entry)
+			}
+			if len(types.Entries) > 0 {
+				toc = append(toc, types)
+			}
+		}
+		if funcs := filtered_funcs(pkg.Funcs); options.Funcs && len(funcs) > 0 {
+			var funcs_entry = &TocEntry{Title: "Functions", Anchor: HeadingSlug("Functions")}
+			for _, _func := range funcs {
+				funcs_entry.Entries = append(funcs_entry.Entries, &TocEntry{Title: _func.Name, Anchor: Anchor(_func.Name)})
+				examples = append(examples, _func.Examples...)
+			}
+			toc = append(toc, funcs_entry)
+		}
+		if options.Consts && len(pkg.Consts) > 0 {
+			toc = append(toc, &TocEntry{Title: "Constants", Anchor: HeadingSlug("Constants")})
+		}
+		if options.Vars && len(pkg.Vars) > 0 {
+			toc = append(toc, &TocEntry{Title: "Variables", Anchor: HeadingSlug("Vars")})
+		}
+		if examples = append(examples, pkg.Examples...); options.Examples && len(examples) > 0 {
+			var examples_entry = &TocEntry{Title: "Examples"}
+			for _, example := range examples {
+				examples_entry.Entries = append(examples_entry.Entries, &TocEntry{Title: ExampleLabel(example), Anchor: ExampleAnchor(example)})
+			}
+			// The `# Examples` heading is only rendered for the package examples, otherwise the section links to its first example
+			examples_entry.Anchor = examples_entry.Entries[0].Anchor
+			if len(pkg.Examples) > 0 {
+				examples_entry.Anchor = HeadingSlug("Examples")
+			}
+			toc = append(toc, examples_entry)
+		}
+		return
+	}
+}
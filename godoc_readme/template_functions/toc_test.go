@@ -0,0 +1,62 @@
+package template_functions
+
+import (
+	"go/doc"
+	"strings"
+	"testing"
+)
+
+func TestToc(t *testing.T) {
+	var pkg = &doc.Package{
+		Types: []*doc.Type{
+			{Name: "A", Doc: "A doc", Methods: []*doc.Func{{Name: "Close", Doc: "Close doc"}}},
+			{Name: "B", Doc: "B doc", Methods: []*doc.Func{{Name: "Close", Doc: "Close doc"}, {Name: "undocumented"}}},
+			{Name: "C"},
+		},
+		Funcs:  []*doc.Func{{Name: "New", Doc: "New doc"}},
+		Consts: []*doc.Value{{Names: []string{"X"}}},
+	}
+	toc := Toc(TocOptions{Types: true, Methods: true, Funcs: true, Consts: true, Vars: true, SkipEmpty: true})(pkg)
+	if len(toc) != 3 || toc[0].Anchor != "types" || toc[1].Anchor != "functions" || toc[2].Anchor != "constants" {
+		t.Fatalf("unexpected sections %+v", toc)
+	}
+	if types := toc[0].Entries; len(types) != 2 || types[1].Anchor != "B" {
+		t.Fatalf("expected the undocumented type to be skipped, got %+v", types)
+	}
+	if have := toc[0].Entries[1].Entries; len(have) != 1 || have[0].Title != "B.Close" || have[0].Anchor != "B.Close" {
+		t.Errorf("expected the method to be anchored by its qualified name, got %+v", have)
+	}
+	if have := toc[1].Entries[0].Anchor; have != "New" {
+		t.Errorf("have %q, want %q", have, "New")
+	}
+}
+
+func TestTocExamples(t *testing.T) {
+	var pkg = &doc.Package{
+		Types: []*doc.Type{
+			{Name: "A", Doc: "A doc", Examples: []*doc.Example{{Name: "A"}}, Methods: []*doc.Func{{Name: "Close", Doc: "Close doc", Examples: []*doc.Example{{Name: "A_Close"}}}}},
+			{Name: "C", Examples: []*doc.Example{{Name: "C"}}},
+		},
+		Funcs:    []*doc.Func{{Name: "New", Doc: "New doc", Examples: []*doc.Example{{Name: "New"}}}},
+		Examples: []*doc.Example{{Name: ""}},
+	}
+	toc := Toc(TocOptions{Types: true, Methods: true, Funcs: true, Examples: true, SkipEmpty: true})(pkg)
+	var examples = toc[len(toc)-1]
+	if examples.Title != "Examples" || examples.Anchor != "examples" {
+		t.Fatalf("unexpected sections %+v", toc)
+	}
+	var anchors []string
+	for _, entry := range examples.Entries {
+		anchors = append(anchors, entry.Anchor)
+	}
+	if have, want := strings.Join(anchors, ","), "ExampleA,ExampleA_Close,ExampleNew,Example"; have != want {
+		t.Errorf("expected the rendered examples to link to their own anchors, have %q, want %q", have, want)
+	}
+}
+
+func TestTocSkipsEmptySections(t *testing.T) {
+	var pkg = &doc.Package{Types: []*doc.Type{{Name: "C"}}}
+	if toc := Toc(TocOptions{Types: true, SkipEmpty: true})(pkg); len(toc) != 0 {
+		t.Errorf("expected no Types section when every type is skipped, have %+v", toc)
+	}
+}
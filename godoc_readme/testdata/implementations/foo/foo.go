@@ -0,0 +1,12 @@
+// Package foo implements the plugin interface of another package
+package foo
+
+import "github.com/dubbikins/godoc-readme/godoc_readme/testdata/implementations/plugin"
+
+// Foo is a plugin
+type Foo struct{}
+
+// Init initializes the plugin
+func (Foo) Init(config *plugin.Config) error {
+	return nil
+}
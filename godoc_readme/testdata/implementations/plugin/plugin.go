@@ -0,0 +1,12 @@
+// Package plugin declares an interface that's implemented in another package
+package plugin
+
+// Config configures a plugin
+type Config struct {
+	Name string
+}
+
+// Plugin is implemented by the plugins, its method uses a named type of this package
+type Plugin interface {
+	Init(config *Config) error
+}
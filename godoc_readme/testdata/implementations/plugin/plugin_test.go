@@ -0,0 +1,8 @@
+package plugin
+
+import "testing"
+
+// The test file makes the loader return a test variant of the package, whose types aren't the ones the other packages import
+func TestConfig(t *testing.T) {
+	_ = Config{Name: "test"}
+}
@@ -0,0 +1,18 @@
+/*
+Package regions quotes the godoc-readme region markers in its doc
+
+Add `<!-- godoc-readme:start -->` and `<!-- godoc-readme:end -->` to a README to keep its hand-written content:
+
+```markdown
+# My Project
+
+<!-- godoc-readme:start -->
+<!-- godoc-readme:end -->
+```
+*/
+package regions
+
+// Region is a region of a README
+type Region struct {
+	Name string
+}
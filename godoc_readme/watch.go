@@ -0,0 +1,207 @@
+package godoc_readme
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"golang.org/x/tools/go/packages"
+)
+
+// The time to wait after the last change before the READMEs are regenerated, so that saving several files at once only regenerates once
+const watch_debounce = 250 * time.Millisecond
+
+/*
+Watch regenerates the READMEs when the go files of a package or the partials of the template directory change, until the context is cancelled.
+
+Only the packages whose directory changed are reloaded and regenerated, a change to a `*.tmpl` partial regenerates every package.
+With a recursive package pattern, i.e. `./...`, the directories created while watching are watched too, so a new package gets its README as soon as it has a go file.
+Changes are debounced, so saving several files at once regenerates each package once.
+Errors while regenerating, i.e. a syntax error in a file that's being edited, are printed and the watcher keeps running.
+Watch doesn't generate the READMEs before the first change, call `Generate` first for that.
+
+	readme, err := NewReadme(func(ro *ReadmeOptions) {
+		ro.PackageDir = "./..."
+	})
+	if err = readme.Generate(); err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return readme.Watch(ctx)
+*/
+func (readme *Readme) Watch(ctx context.Context) (err error) {
+	var watcher *fsnotify.Watcher
+	if watcher, err = fsnotify.NewWatcher(); err != nil {
+		return
+	}
+	defer watcher.Close()
+	var template_dirs = readme.template_dirs()
+	for template_dir := range template_dirs {
+		if err = watcher.Add(template_dir); err != nil {
+			return fmt.Errorf("failed to watch template directory %q: %w", template_dir, err)
+		}
+	}
+	var package_dirs = map[string]bool{}
+	for _, pkg := range readme.Pkgs {
+		package_dirs[filepath.Dir(pkg.GoFiles[0])] = true
+	}
+	for package_dir := range package_dirs {
+		if err = watcher.Add(package_dir); err != nil {
+			return fmt.Errorf("failed to watch package directory %q: %w", package_dir, err)
+		}
+	}
+	// With a recursive pattern, i.e. `./...`, the directories created under its root are watched too so new packages are picked up
+	var root_dir, recursive = readme.watch_root()
+	if recursive {
+		if err = watch_dirs(watcher, root_dir, package_dirs); err != nil {
+			return fmt.Errorf("failed to watch directory %q: %w", root_dir, err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Watching %d directories for changes...\n", len(package_dirs))
+
+	var debounce = time.NewTimer(watch_debounce)
+	debounce.Stop()
+	defer debounce.Stop()
+	var changed_dirs = map[string]bool{}
+	var templates_changed bool
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			var dir = filepath.Dir(event.Name)
+			if info, err := os.Stat(event.Name); recursive && event.Op.Has(fsnotify.Create) && err == nil && info.IsDir() {
+				if err = watch_dirs(watcher, event.Name, package_dirs); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+				}
+				// The go files that were created with the directory, i.e. when it's copied or checked out, are missed by the watcher
+				if go_files, _ := filepath.Glob(filepath.Join(event.Name, "*.go")); len(go_files) > 0 {
+					changed_dirs[event.Name] = true
+					debounce.Reset(watch_debounce)
+				}
+				continue
+			}
+			switch {
+			case template_dirs[dir] && strings.HasSuffix(event.Name, ".tmpl"):
+				templates_changed = true
+			case package_dirs[dir] && strings.HasSuffix(event.Name, ".go"):
+				changed_dirs[dir] = true
+			default:
+				// i.e. the README that was just written to the package directory
+				continue
+			}
+			debounce.Reset(watch_debounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintln(os.Stderr, err)
+		case <-debounce.C:
+			if err := readme.regenerate(changed_dirs, templates_changed); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			changed_dirs = map[string]bool{}
+			templates_changed = false
+		}
+	}
+}
+
+// watch_root returns the absolute root directory of the package pattern if it's recursive, i.e. the module root for `./...`
+func (readme *Readme) watch_root() (root_dir string, recursive bool) {
+	var pattern = filepath.ToSlash(readme.options.PackageDir)
+	if pattern != "..." && !strings.HasSuffix(pattern, "/...") {
+		return "", false
+	}
+	var err error
+	if root_dir, err = filepath.Abs(filepath.Join(readme.options.Dir, filepath.FromSlash(strings.TrimSuffix(pattern, "...")))); err != nil {
+		return "", false
+	}
+	return root_dir, true
+}
+
+// watch_dirs adds the directory and the directories in it to the watcher and to the package directories
+// The directories the go tool ignores, i.e. `testdata`, `vendor` and the ones starting with `.` or `_`, are skipped
+func watch_dirs(watcher *fsnotify.Watcher, dir string, package_dirs map[string]bool) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return err
+		}
+		if name := entry.Name(); path != dir && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor") {
+			return filepath.SkipDir
+		}
+		if package_dirs[path] {
+			return nil
+		}
+		package_dirs[path] = true
+		return watcher.Add(path)
+	})
+}
+
+// template_dirs returns the absolute paths of the template directories of the options and the config file overrides
+func (readme *Readme) template_dirs() map[string]bool {
+	var dirs = []string{readme.options.TemplateDir}
+	if readme.options.Config != nil {
+		for _, options := range readme.options.Config.Packages {
+			dirs = append(dirs, options.TemplateDir)
+		}
+	}
+	var template_dirs = map[string]bool{}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		if abs_dir, err := filepath.Abs(dir); err == nil {
+			template_dirs[abs_dir] = true
+		}
+	}
+	return template_dirs
+}
+
+// regenerate reloads the packages in the changed directories and generates their READMEs
+// If *all* is true, the READMEs of every package are generated
+func (readme *Readme) regenerate(changed_dirs map[string]bool, all bool) (err error) {
+	if len(changed_dirs) > 0 {
+		var patterns []string
+		for dir := range changed_dirs {
+			patterns = append(patterns, dir)
+		}
+		var pkgs []*packages.Package
+		if pkgs, err = readme.load_packages(patterns...); err != nil {
+			return
+		}
+		// The packages are still registered with a syntax error so that the error is visible in the output
+		packages.PrintErrors(pkgs)
+		for name, pkg := range readme.Pkgs {
+			if changed_dirs[filepath.Dir(pkg.GoFiles[0])] {
+				delete(readme.Pkgs, name)
+			}
+		}
+		readme.add_packages(pkgs)
+	}
+	var pkgs []*packages.Package
+	for _, pkg := range readme.Packages {
+		if all || changed_dirs[filepath.Dir(pkg.GoFiles[0])] {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	var names []string
+	for _, pkg := range pkgs {
+		names = append(names, pkg.PkgPath)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "Regenerating %s\n", strings.Join(names, ", "))
+	return readme.generate_packages(pkgs)
+}
@@ -0,0 +1,67 @@
+package godoc_readme
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestRegenerate(t *testing.T) {
+	var written []string
+	readme, err := NewReadme(func(ro *ReadmeOptions) {
+		ro.PackageDir = "./..."
+		ro.WriteFunc = func(package_readme *PackageReadme, content []byte) error {
+			written = append(written, package_readme.Pkg.PkgPath)
+			return nil
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = readme.regenerate(map[string]bool{cwd: true}, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 1 || written[0] != "github.com/dubbikins/godoc-readme/godoc_readme" {
+		t.Errorf("expected only the package in the changed directory to be regenerated, got %q", written)
+	}
+	written = nil
+	if err = readme.regenerate(map[string]bool{}, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != len(readme.Pkgs) {
+		t.Errorf("expected every package to be regenerated when the templates change, got %q", written)
+	}
+}
+
+func TestWatchDirs(t *testing.T) {
+	var root_dir = t.TempDir()
+	for _, dir := range []string{"api/v2", "testdata/fixture", ".git/objects", "_scratch"} {
+		if err := os.MkdirAll(filepath.Join(root_dir, filepath.FromSlash(dir)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+	var package_dirs = map[string]bool{}
+	if err = watch_dirs(watcher, root_dir, package_dirs); err != nil {
+		t.Fatal(err)
+	}
+	var want = []string{root_dir, filepath.Join(root_dir, "api"), filepath.Join(root_dir, "api", "v2")}
+	if len(package_dirs) != len(want) || len(watcher.WatchList()) != len(want) {
+		t.Fatalf("expected %q to be watched, got %v", want, watcher.WatchList())
+	}
+	for _, dir := range want {
+		if !package_dirs[dir] {
+			t.Errorf("expected %q to be watched", dir)
+		}
+	}
+}